@@ -0,0 +1,362 @@
+package napi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/vmihailenco/msgpack/v5"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	defaultCacheTTL    = time.Minute * 5
+	defaultCachePrefix = "napi:cache"
+	cacheQueriesTag    = "queries"
+)
+
+// CacheObserver is notified of every cache lookup, e.g. to export hit and miss counters. Satisfied by *middleware.Prometheus.
+type CacheObserver interface {
+	ObserveCache(name string, hit bool)
+}
+
+// CacheOptions configures a CachedRepository.
+type CacheOptions struct {
+	// Name identifies the cache in redis keys and metrics. Defaults to the table name of the model.
+	Name string
+	// Prefix of every redis key. Defaults to "napi:cache".
+	Prefix string
+	// TTL of models cached by id. Defaults to 5 minutes.
+	TTL time.Duration
+	// QueryTTL of cached query results. Defaults to TTL.
+	QueryTTL time.Duration
+	// Observer is notified of cache hits and misses.
+	Observer CacheObserver
+}
+
+// CachedRepository is a Repository[T] decorator caching reads in redis.
+//
+// Find is cached by id, while FindBy, First, Exists, Count and Paginate are cached by a key derived from their arguments.
+// Entries are tagged: Create and CreateMany invalidate the cached queries, Update and Delete also invalidate the cached id.
+// Invalidating a tag also bumps its version, so a read started before the invalidation never caches what it got.
+// Concurrent misses of the same key share a single call to the underlying repository.
+type CachedRepository[T any] struct {
+	repo   Repository[T]
+	client redis.UniversalClient
+	opts   CacheOptions
+	ctx    context.Context
+	flight *singleFlight
+}
+
+// NewCachedRepository wraps repo with a redis cache.
+//
+//	accounts := napi.NewCachedRepository[Account](napi.NewRepository[Account](driver), rdb, napi.CacheOptions{Observer: srv.Prometheus()})
+func NewCachedRepository[T any](repo Repository[T], client redis.UniversalClient, opts CacheOptions) *CachedRepository[T] {
+	if opts.Name == "" {
+		if s, err := schema.Parse(new(T), &sync.Map{}, schema.NamingStrategy{}); err == nil {
+			opts.Name = s.Table
+		} else {
+			opts.Name = fmt.Sprintf("%T", *new(T))
+		}
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = defaultCachePrefix
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = defaultCacheTTL
+	}
+	if opts.QueryTTL <= 0 {
+		opts.QueryTTL = opts.TTL
+	}
+
+	return &CachedRepository[T]{
+		repo:   repo,
+		client: client,
+		opts:   opts,
+		ctx:    context.Background(),
+		flight: &singleFlight{calls: map[string]*flightCall{}},
+	}
+}
+
+// WithContext returns a copy of the repository using ctx for redis commands.
+func (c *CachedRepository[T]) WithContext(ctx context.Context) *CachedRepository[T] {
+	cp := *c
+	cp.ctx = ctx
+	return &cp
+}
+
+// Find gets a model by primary key, cached by id.
+func (c *CachedRepository[T]) Find(id interface{}) (*T, error) {
+	return cacheRemember(c, c.key("id", fmt.Sprint(id)), c.opts.TTL, []string{c.idTag(id)}, func() (*T, error) {
+		return c.repo.Find(id)
+	})
+}
+
+// FindBy gets every model matching the conditions, cached by query.
+func (c *CachedRepository[T]) FindBy(conds ...interface{}) ([]T, error) {
+	return cacheQuery(c, "find_by", conds, func() ([]T, error) {
+		return c.repo.FindBy(conds...)
+	})
+}
+
+// First gets the first model matching the conditions, cached by query.
+func (c *CachedRepository[T]) First(conds ...interface{}) (*T, error) {
+	return cacheQuery(c, "first", conds, func() (*T, error) {
+		return c.repo.First(conds...)
+	})
+}
+
+// Create creates the model and invalidates the cached queries.
+func (c *CachedRepository[T]) Create(model *T) error {
+	if err := c.repo.Create(model); err != nil {
+		return err
+	}
+	return c.Invalidate(cacheQueriesTag)
+}
+
+// CreateMany creates the models and invalidates the cached queries.
+func (c *CachedRepository[T]) CreateMany(models []T) error {
+	if err := c.repo.CreateMany(models); err != nil {
+		return err
+	}
+	return c.Invalidate(cacheQueriesTag)
+}
+
+// Update updates the model and invalidates it and the cached queries.
+func (c *CachedRepository[T]) Update(id interface{}, values UpdateMap) (*T, error) {
+	model, err := c.repo.Update(id, values)
+	if err != nil {
+		return nil, err
+	}
+	return model, c.Invalidate(c.idTag(id), cacheQueriesTag)
+}
+
+// Delete deletes the model and invalidates it and the cached queries.
+func (c *CachedRepository[T]) Delete(id interface{}) error {
+	if err := c.repo.Delete(id); err != nil {
+		return err
+	}
+	return c.Invalidate(c.idTag(id), cacheQueriesTag)
+}
+
+// Exists checks if any model matches the conditions, cached by query.
+func (c *CachedRepository[T]) Exists(conds ...interface{}) (bool, error) {
+	return cacheQuery(c, "exists", conds, func() (bool, error) {
+		return c.repo.Exists(conds...)
+	})
+}
+
+// Count counts the models matching the conditions, cached by query.
+func (c *CachedRepository[T]) Count(conds ...interface{}) (int64, error) {
+	return cacheQuery(c, "count", conds, func() (int64, error) {
+		return c.repo.Count(conds...)
+	})
+}
+
+// Paginate lists a page of models, cached by the paginater and options.
+func (c *CachedRepository[T]) Paginate(p *Paginater, opts ScopeOptions) ([]T, int64, error) {
+	type page struct {
+		Items []T
+		Total int64
+	}
+
+	args := []interface{}{p, opts}
+	res, err := cacheQuery(c, "paginate", args, func() (page, error) {
+		items, total, err := c.repo.Paginate(p, opts)
+		return page{Items: items, Total: total}, err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return res.Items, res.Total, nil
+}
+
+// Invalidate deletes every entry cached with one of the tags.
+func (c *CachedRepository[T]) Invalidate(tags ...string) error {
+	for _, tag := range tags {
+		// bumped first, so a read in progress sees the change before its entry can be stored
+		versionKey := c.key("version", tag)
+		if _, err := c.client.TxPipelined(c.ctx, func(pipe redis.Pipeliner) error {
+			pipe.Incr(c.ctx, versionKey)
+			pipe.Expire(c.ctx, versionKey, c.tagTTL())
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		tagKey := c.key("tag", tag)
+		keys, err := c.client.SMembers(c.ctx, tagKey).Result()
+		if err != nil {
+			return err
+		}
+		if err = c.client.Del(c.ctx, append(keys, tagKey)...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// key builds a redis key in the namespace of the cache.
+func (c *CachedRepository[T]) key(kind, value string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", c.opts.Prefix, c.opts.Name, kind, value)
+}
+
+// idTag is the tag of the entry caching a model by id.
+func (c *CachedRepository[T]) idTag(id interface{}) string {
+	return "id:" + fmt.Sprint(id)
+}
+
+// tagTTL is the TTL of the tags and their versions, long enough to outlive every entry they tag.
+func (c *CachedRepository[T]) tagTTL() time.Duration {
+	if c.opts.QueryTTL > c.opts.TTL {
+		return c.opts.QueryTTL
+	}
+	return c.opts.TTL
+}
+
+// versionKeys are the keys of the versions of the tags.
+func (c *CachedRepository[T]) versionKeys(tags []string) []string {
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = c.key("version", tag)
+	}
+	return keys
+}
+
+// versions reads the current versions of the tags, to be checked by store.
+func (c *CachedRepository[T]) versions(tags []string) ([]interface{}, error) {
+	return c.client.MGet(c.ctx, c.versionKeys(tags)...).Result()
+}
+
+// store caches the value and adds its key to the tags, unless one of the tags was invalidated since versions were read.
+// Failures are ignored, the value is simply not cached.
+func (c *CachedRepository[T]) store(key string, value []byte, ttl time.Duration, tags []string, versions []interface{}) {
+	versionKeys := c.versionKeys(tags)
+	_ = c.client.Watch(c.ctx, func(tx *redis.Tx) error {
+		current, err := tx.MGet(c.ctx, versionKeys...).Result()
+		if err != nil || !reflect.DeepEqual(current, versions) {
+			return err
+		}
+
+		// the transaction fails if a version changes after the check
+		_, err = tx.TxPipelined(c.ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(c.ctx, key, value, ttl)
+			for _, tag := range tags {
+				tagKey := c.key("tag", tag)
+				pipe.SAdd(c.ctx, tagKey, key)
+				pipe.Expire(c.ctx, tagKey, c.tagTTL())
+			}
+			return nil
+		})
+		return err
+	}, versionKeys...)
+}
+
+// cacheQuery caches the result of fn by a hash of the method name and its arguments.
+// Arguments are encoded with msgpack, which ignores the json tags, so fields hidden with json:"-" are part of the key.
+func cacheQuery[T any, V any](c *CachedRepository[T], method string, args []interface{}, fn func() (V, error)) (V, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	// maps are encoded in a stable order, or the same arguments could hash to different keys
+	enc.SetSortMapKeys(true)
+	if err := enc.Encode(args); err != nil {
+		return fn()
+	}
+	sum := sha1.Sum(buf.Bytes())
+	key := c.key(method, hex.EncodeToString(sum[:]))
+	return cacheRemember(c, key, c.opts.QueryTTL, []string{cacheQueriesTag}, fn)
+}
+
+// cacheRemember gets the value cached at key, or calls fn and caches its result. Errors are never cached.
+// Values are encoded with msgpack, which ignores the json tags, so fields hidden from responses with json:"-" are cached too.
+// Redis being unavailable is treated as a miss, so the cache never fails a read the repository would serve.
+func cacheRemember[T any, V any](c *CachedRepository[T], key string, ttl time.Duration, tags []string, fn func() (V, error)) (V, error) {
+	var value V
+	if raw, err := c.client.Get(c.ctx, key).Bytes(); err == nil && msgpack.Unmarshal(raw, &value) == nil {
+		c.observe(true)
+		return value, nil
+	}
+	c.observe(false)
+
+	ran := false
+	raw, err := c.flight.do(key, func() ([]byte, error) {
+		// read before fn, so an invalidation while it runs keeps its result out of the cache
+		versions, versionsErr := c.versions(tags)
+
+		v, err := fn()
+		if err != nil {
+			return nil, err
+		}
+		value, ran = v, true
+
+		raw, err := msgpack.Marshal(v)
+		if err != nil {
+			// not cacheable, the other callers run fn on their own
+			return nil, nil
+		}
+		if versionsErr == nil {
+			c.store(key, raw, ttl, tags, versions)
+		}
+		return raw, nil
+	})
+	if ran || err != nil {
+		return value, err
+	}
+	if raw == nil {
+		return fn()
+	}
+
+	// every other caller decodes its own copy of the shared result
+	err = msgpack.Unmarshal(raw, &value)
+	return value, err
+}
+
+// observe notifies the observer of a lookup.
+func (c *CachedRepository[T]) observe(hit bool) {
+	if c.opts.Observer != nil {
+		c.opts.Observer.ObserveCache(c.opts.Name, hit)
+	}
+}
+
+// singleFlight collapses concurrent calls with the same key into one.
+type singleFlight struct {
+	lock  sync.Mutex
+	calls map[string]*flightCall
+}
+
+// flightCall is a call in progress or completed.
+type flightCall struct {
+	wg  sync.WaitGroup
+	val []byte
+	err error
+}
+
+// do calls fn once for all the concurrent callers of the same key and shares its result.
+func (g *singleFlight) do(key string, fn func() ([]byte, error)) ([]byte, error) {
+	g.lock.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.lock.Unlock()
+		call.wg.Wait()
+		return call.val, call.err
+	}
+	call := &flightCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.lock.Unlock()
+
+	defer func() {
+		g.lock.Lock()
+		delete(g.calls, key)
+		g.lock.Unlock()
+		call.wg.Done()
+	}()
+
+	call.val, call.err = fn()
+	return call.val, call.err
+}
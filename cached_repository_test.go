@@ -0,0 +1,245 @@
+package napi
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/netr/napi/sweets"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// testCountingRepository counts the calls reaching the underlying repository.
+type testCountingRepository struct {
+	*MemoryRepository[testMemoryModel]
+	calls int32
+	delay time.Duration
+	// after is called once Find has read the model
+	after func()
+}
+
+func (r *testCountingRepository) Find(id interface{}) (*testMemoryModel, error) {
+	atomic.AddInt32(&r.calls, 1)
+	time.Sleep(r.delay)
+	model, err := r.MemoryRepository.Find(id)
+	if r.after != nil {
+		r.after()
+	}
+	return model, err
+}
+
+func (r *testCountingRepository) FindBy(conds ...interface{}) ([]testMemoryModel, error) {
+	atomic.AddInt32(&r.calls, 1)
+	return r.MemoryRepository.FindBy(conds...)
+}
+
+func (r *testCountingRepository) Count(conds ...interface{}) (int64, error) {
+	atomic.AddInt32(&r.calls, 1)
+	return r.MemoryRepository.Count(conds...)
+}
+
+type testCacheObserver struct {
+	lock         sync.Mutex
+	hits, misses int
+}
+
+func (o *testCacheObserver) ObserveCache(_ string, hit bool) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if hit {
+		o.hits++
+	} else {
+		o.misses++
+	}
+}
+
+func testCachedRepository(t *testing.T) (*CachedRepository[testMemoryModel], *testCountingRepository, *testCacheObserver) {
+	suite := &sweets.RedisSuite{}
+	if err := suite.NewRedisSuite(t); err != nil {
+		t.Fatal(err)
+	}
+
+	inner := &testCountingRepository{MemoryRepository: testMemoryRepository(t)}
+	observer := &testCacheObserver{}
+	return NewCachedRepository[testMemoryModel](inner, suite.DB(), CacheOptions{Observer: observer}), inner, observer
+}
+
+func TestCachedRepository_Find(t *testing.T) {
+	repo, inner, observer := testCachedRepository(t)
+
+	for i := 0; i < 3; i++ {
+		model, err := repo.Find(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", model.Username)
+	}
+	assert.Equal(t, int32(1), inner.calls)
+	assert.Equal(t, 2, observer.hits)
+	assert.Equal(t, 1, observer.misses)
+
+	_, err := repo.Find(42)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, _ = repo.Find(42)
+	assert.Equal(t, int32(3), inner.calls)
+}
+
+func TestCachedRepository_ShouldCacheFieldsHiddenFromJson(t *testing.T) {
+	type testSecretModel struct {
+		ID        uint
+		Username  string
+		Password  string    `json:"-"`
+		UpdatedAt time.Time `json:"-"`
+	}
+
+	suite := &sweets.RedisSuite{}
+	if err := suite.NewRedisSuite(t); err != nil {
+		t.Fatal(err)
+	}
+	inner := NewMemoryRepository[testSecretModel]()
+	assert.NoError(t, inner.Create(&testSecretModel{Username: "alice", Password: "secret"}))
+	repo := NewCachedRepository[testSecretModel](inner, suite.DB(), CacheOptions{})
+
+	for i := 0; i < 2; i++ {
+		model, err := repo.Find(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "secret", model.Password)
+		assert.False(t, model.UpdatedAt.IsZero())
+
+		models, err := repo.FindBy(testSecretModel{Username: "alice"})
+		assert.NoError(t, err)
+		assert.Equal(t, "secret", models[0].Password)
+	}
+}
+
+func TestCachedRepository_ShouldUseDefaultOptions(t *testing.T) {
+	repo, _, _ := testCachedRepository(t)
+
+	assert.Equal(t, "test_memory_models", repo.opts.Name)
+	assert.Equal(t, defaultCacheTTL, repo.opts.QueryTTL)
+
+	_, _ = repo.Find(1)
+	ttl := repo.client.TTL(repo.ctx, "napi:cache:test_memory_models:id:1").Val()
+	assert.Equal(t, defaultCacheTTL, ttl)
+}
+
+func TestCachedRepository_ShouldCacheQueries(t *testing.T) {
+	repo, inner, _ := testCachedRepository(t)
+
+	for i := 0; i < 2; i++ {
+		models, err := repo.FindBy(&testMemoryModel{Status: "active"})
+		assert.NoError(t, err)
+		assert.Len(t, models, 2)
+
+		count, err := repo.Count(&testMemoryModel{Status: "banned"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	}
+	assert.Equal(t, int32(2), inner.calls)
+
+	_, _ = repo.FindBy(&testMemoryModel{Status: "banned"})
+	assert.Equal(t, int32(3), inner.calls)
+}
+
+func TestCachedRepository_ShouldKeyQueriesByFieldsHiddenFromJson(t *testing.T) {
+	type query struct {
+		Status string
+		Secret string `json:"-"`
+	}
+	repo, _, _ := testCachedRepository(t)
+
+	calls := 0
+	count := func() (int, error) {
+		calls++
+		return calls, nil
+	}
+	first, _ := cacheQuery(repo, "count", []interface{}{query{Status: "active", Secret: "a"}}, count)
+	second, _ := cacheQuery(repo, "count", []interface{}{query{Status: "active", Secret: "b"}}, count)
+	again, _ := cacheQuery(repo, "count", []interface{}{query{Status: "active", Secret: "a"}}, count)
+
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 2, second)
+	assert.Equal(t, 1, again)
+}
+
+func TestCachedRepository_ShouldNotCacheAReadInvalidatedWhileRunning(t *testing.T) {
+	repo, inner, _ := testCachedRepository(t)
+	inner.after = func() {
+		inner.after = nil
+		_, err := repo.Update(1, UpdateMap{"status": "banned"})
+		assert.NoError(t, err)
+	}
+
+	model, err := repo.Find(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "active", model.Status)
+
+	model, err = repo.Find(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "banned", model.Status)
+	assert.Equal(t, int32(2), inner.calls)
+}
+
+func TestCachedRepository_ShouldInvalidateOnWrites(t *testing.T) {
+	repo, inner, _ := testCachedRepository(t)
+
+	_, _ = repo.Find(1)
+	_, _ = repo.Find(2)
+	_, _ = repo.FindBy(&testMemoryModel{Status: "active"})
+
+	assert.NoError(t, repo.Create(&testMemoryModel{Username: "dave", Status: "active"}))
+	models, _ := repo.FindBy(&testMemoryModel{Status: "active"})
+	assert.Len(t, models, 3)
+	_, _ = repo.Find(1)
+	assert.Equal(t, int32(4), inner.calls)
+
+	updated, err := repo.Update(1, UpdateMap{"status": "banned"})
+	assert.NoError(t, err)
+	assert.Equal(t, "banned", updated.Status)
+	model, _ := repo.Find(1)
+	assert.Equal(t, "banned", model.Status)
+	_, _ = repo.Find(2)
+	assert.Equal(t, int32(5), inner.calls)
+
+	assert.NoError(t, repo.Delete(2))
+	_, err = repo.Find(2)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	models, _ = repo.FindBy(&testMemoryModel{Status: "active"})
+	assert.Len(t, models, 2)
+}
+
+func TestCachedRepository_Paginate(t *testing.T) {
+	repo, _, _ := testCachedRepository(t)
+	opts := ScopeOptions{Sort: SortRules{"username": {}}}
+
+	p := &Paginater{CanPaginate: CanPaginate{Page: 1, Limit: 2}, CanOrder: CanOrder{Sort: "-username"}}
+	models, total, err := repo.Paginate(p, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, "carol", models[0].Username)
+
+	p.Filters = Filters{{Field: "status", Operator: FilterEq, Values: []string{"banned"}}}
+	models, total, err = repo.Paginate(p, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, "bob", models[0].Username)
+}
+
+func TestCachedRepository_ShouldCollapseConcurrentMisses(t *testing.T) {
+	repo, inner, _ := testCachedRepository(t)
+	inner.delay = time.Millisecond * 50
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			model, err := repo.Find(3)
+			assert.NoError(t, err)
+			assert.Equal(t, "carol", model.Username)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&inner.calls))
+}
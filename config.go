@@ -0,0 +1,243 @@
+package napi
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"gopkg.in/yaml.v3"
+)
+
+// Config server settings that can be loaded from env vars and YAML/JSON files. See LoadConfig and NewServerFromConfig.
+type Config struct {
+	AppName         string   `json:"app_name" yaml:"app_name" env:"APP_NAME" validate:"required"`
+	Port            int      `json:"port" yaml:"port" env:"PORT" validate:"min=0,max=65535"`
+	AdminPort       int      `json:"admin_port" yaml:"admin_port" env:"ADMIN_PORT" validate:"min=0,max=65535"`
+	ReadTimeout     Duration `json:"read_timeout" yaml:"read_timeout" env:"READ_TIMEOUT" validate:"min=0"`
+	WriteTimeout    Duration `json:"write_timeout" yaml:"write_timeout" env:"WRITE_TIMEOUT" validate:"min=0"`
+	ShutdownTimeout Duration `json:"shutdown_timeout" yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" validate:"min=0"`
+
+	// CORSOrigins enables the CORS middleware for the given origins when not empty. Comma separated in env vars.
+	CORSOrigins []string `json:"cors_origins" yaml:"cors_origins" env:"CORS_ORIGINS" validate:"dive,required"`
+	// LimiterMax enables the limiter middleware when greater than 0.
+	LimiterMax        int      `json:"limiter_max" yaml:"limiter_max" env:"LIMITER_MAX" validate:"min=0"`
+	LimiterExpiration Duration `json:"limiter_expiration" yaml:"limiter_expiration" env:"LIMITER_EXPIRATION" validate:"min=0"`
+	// LogFormat enables the logger middleware with the given format when not empty.
+	LogFormat string `json:"log_format" yaml:"log_format" env:"LOG_FORMAT"`
+
+	BaseMiddlewares bool `json:"base_middlewares" yaml:"base_middlewares" env:"BASE_MIDDLEWARES"`
+	CatchAll        bool `json:"catch_all" yaml:"catch_all" env:"CATCH_ALL"`
+	Prometheus      bool `json:"prometheus" yaml:"prometheus" env:"PROMETHEUS"`
+	Pprof           bool `json:"pprof" yaml:"pprof" env:"PPROF"`
+	Health          bool `json:"health" yaml:"health" env:"HEALTH"`
+	// CursorSecret signs the cursor tokens of CursorPaginate. A random secret is used when empty, only fit for development.
+	CursorSecret string `json:"cursor_secret" yaml:"cursor_secret" env:"CURSOR_SECRET"`
+	// Production hides the messages of unknown errors sent by the ErrorHandler.
+	Production bool `json:"production" yaml:"production" env:"PRODUCTION"`
+	// ProblemDetails sends every error response as an RFC 7807 application/problem+json document.
+	ProblemDetails bool `json:"problem_details" yaml:"problem_details" env:"PROBLEM_DETAILS"`
+}
+
+// DefaultConfig the defaults used by LoadConfig. Timeouts match DefaultFiberConfig, and the base middlewares, logger, health endpoints and catch all handler are enabled.
+func DefaultConfig() Config {
+	return Config{
+		AppName:           "napi",
+		Port:              1337,
+		ReadTimeout:       Duration(time.Second * 30),
+		WriteTimeout:      Duration(time.Second * 30),
+		ShutdownTimeout:   Duration(defaultShutdownTimeout),
+		LimiterExpiration: Duration(time.Minute),
+		LogFormat:         defaultLoggerConfig().Format,
+		BaseMiddlewares:   true,
+		CatchAll:          true,
+		Health:            true,
+	}
+}
+
+// LoadConfig loads a Config starting from DefaultConfig. The files are applied in order and may be YAML (.yaml, .yml) or JSON (.json); missing files are skipped. Env vars are applied last, named after the env tags with an optional prefix, e.g. prefix "API" reads API_PORT.
+func LoadConfig(prefix string, files ...string) (Config, error) {
+	cfg := DefaultConfig()
+
+	for _, file := range files {
+		if err := cfg.loadFile(file); err != nil {
+			return cfg, err
+		}
+	}
+
+	if err := cfg.loadEnv(prefix); err != nil {
+		return cfg, err
+	}
+
+	return cfg, cfg.Validate()
+}
+
+// Validate checks the config with the same validator used for requests.
+func (cfg Config) Validate() error {
+	if bag := validateRequest(cfg); len(bag) > 0 {
+		return fmt.Errorf("invalid config: %w", (&ValidationError{bag: bag}).Err())
+	}
+	return nil
+}
+
+// NewServerFromConfig creates a new server from a Config. The given options are applied after the ones derived from the config.
+func NewServerFromConfig(cfg Config, opts ...ServerOption) *Server {
+	fiberCfg := DefaultFiberConfig(cfg.AppName)
+	fiberCfg.ReadTimeout = time.Duration(cfg.ReadTimeout)
+	fiberCfg.WriteTimeout = time.Duration(cfg.WriteTimeout)
+	fiberCfg.ErrorHandler = DefaultErrors.Handler(cfg.Production)
+
+	cfgOpts := []ServerOption{
+		WithPort(cfg.Port),
+		WithShutdownTimeout(time.Duration(cfg.ShutdownTimeout)),
+	}
+	if cfg.AdminPort > 0 {
+		cfgOpts = append(cfgOpts, WithAdminPort(cfg.AdminPort))
+	}
+	if cfg.CursorSecret != "" {
+		cfgOpts = append(cfgOpts, WithCursorSecret([]byte(cfg.CursorSecret)))
+	}
+	if cfg.ProblemDetails {
+		cfgOpts = append(cfgOpts, WithProblemDetails())
+	}
+	if cfg.BaseMiddlewares {
+		cfgOpts = append(cfgOpts, WithBaseMiddlewares())
+	}
+	if cfg.LogFormat != "" {
+		loggerCfg := defaultLoggerConfig()
+		loggerCfg.Format = cfg.LogFormat
+		cfgOpts = append(cfgOpts, WithLogger(loggerCfg))
+	}
+	if len(cfg.CORSOrigins) > 0 {
+		cfgOpts = append(cfgOpts, WithCORS(cors.Config{AllowOrigins: strings.Join(cfg.CORSOrigins, ",")}))
+	}
+	if cfg.LimiterMax > 0 {
+		cfgOpts = append(cfgOpts, WithLimiter(limiter.Config{
+			Max:        cfg.LimiterMax,
+			Expiration: time.Duration(cfg.LimiterExpiration),
+		}))
+	}
+	if cfg.Prometheus {
+		cfgOpts = append(cfgOpts, WithPrometheus())
+	}
+	if cfg.Pprof {
+		cfgOpts = append(cfgOpts, WithPprof())
+	}
+	if cfg.Health {
+		cfgOpts = append(cfgOpts, WithHealth())
+	}
+	if cfg.CatchAll {
+		cfgOpts = append(cfgOpts, WithCatchAll())
+	}
+
+	return NewServer(fiberCfg, append(cfgOpts, opts...)...)
+}
+
+// loadFile decodes a YAML or JSON file over the config. Missing files are skipped.
+func (cfg *Config) loadFile(file string) error {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, cfg)
+	case ".json":
+		err = json.Unmarshal(b, cfg)
+	default:
+		return fmt.Errorf("config: unsupported file type: %s", file)
+	}
+	if err != nil {
+		return fmt.Errorf("config: decoding %s: %w", file, err)
+	}
+	return nil
+}
+
+// loadEnv overrides the config fields with the env vars named in their env tags.
+func (cfg *Config) loadEnv(prefix string) error {
+	v := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := field.Tag.Get("env")
+		if name == "" {
+			continue
+		}
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := setFieldFromString(v.Field(i), raw); err != nil {
+			return fmt.Errorf("config: parsing %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setFieldFromString parses a raw env var value into a config field.
+func setFieldFromString(fv reflect.Value, raw string) error {
+	if u, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		fv.Set(reflect.ValueOf(items))
+	default:
+		return errors.New("unsupported field type: " + fv.Kind().String())
+	}
+	return nil
+}
+
+// Duration a time.Duration that is decoded from strings like "30s" in env vars, YAML and JSON.
+type Duration time.Duration
+
+// UnmarshalText parses the duration with time.ParseDuration.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalText formats the duration like time.Duration.String.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
@@ -0,0 +1,129 @@
+package napi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfig_ShouldUseDefaults(t *testing.T) {
+	cfg, err := LoadConfig("NAPI_TEST")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, DefaultConfig(), cfg)
+}
+
+func TestLoadConfig_ShouldLoadYAMLFile(t *testing.T) {
+	file := writeConfigFile(t, "config.yaml", `
+app_name: yaml_app
+port: 8080
+read_timeout: 5s
+cors_origins:
+  - https://example.com
+prometheus: true
+`)
+
+	cfg, err := LoadConfig("NAPI_TEST", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "yaml_app", cfg.AppName)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, Duration(5*time.Second), cfg.ReadTimeout)
+	assert.Equal(t, []string{"https://example.com"}, cfg.CORSOrigins)
+	assert.True(t, cfg.Prometheus)
+	// untouched fields keep their defaults
+	assert.Equal(t, Duration(30*time.Second), cfg.WriteTimeout)
+}
+
+func TestLoadConfig_ShouldLoadJSONFile(t *testing.T) {
+	file := writeConfigFile(t, "config.json", `{"app_name": "json_app", "limiter_max": 20, "limiter_expiration": "30s"}`)
+
+	cfg, err := LoadConfig("NAPI_TEST", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "json_app", cfg.AppName)
+	assert.Equal(t, 20, cfg.LimiterMax)
+	assert.Equal(t, Duration(30*time.Second), cfg.LimiterExpiration)
+}
+
+func TestLoadConfig_ShouldSkipMissingFiles(t *testing.T) {
+	_, err := LoadConfig("NAPI_TEST", filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.NoError(t, err)
+}
+
+func TestLoadConfig_EnvShouldOverrideFiles(t *testing.T) {
+	file := writeConfigFile(t, "config.yaml", "port: 8080\n")
+	t.Setenv("NAPI_TEST_PORT", "9090")
+	t.Setenv("NAPI_TEST_CORS_ORIGINS", "https://a.com, https://b.com")
+	t.Setenv("NAPI_TEST_SHUTDOWN_TIMEOUT", "1m")
+	t.Setenv("NAPI_TEST_PPROF", "true")
+
+	cfg, err := LoadConfig("NAPI_TEST", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 9090, cfg.Port)
+	assert.Equal(t, []string{"https://a.com", "https://b.com"}, cfg.CORSOrigins)
+	assert.Equal(t, Duration(time.Minute), cfg.ShutdownTimeout)
+	assert.True(t, cfg.Pprof)
+}
+
+func TestLoadConfig_ShouldFailOnInvalidEnv(t *testing.T) {
+	t.Setenv("NAPI_TEST_PORT", "not a number")
+
+	_, err := LoadConfig("NAPI_TEST")
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_ShouldFailValidation(t *testing.T) {
+	t.Setenv("NAPI_TEST_APP_NAME", "")
+	t.Setenv("NAPI_TEST_PORT", "70000")
+
+	_, err := LoadConfig("NAPI_TEST")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "app_name")
+		assert.Contains(t, err.Error(), "port")
+	}
+}
+
+func TestNewServerFromConfig_ExpectedBehavior(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.AppName = "from_config"
+	cfg.Port = 8080
+	cfg.ReadTimeout = Duration(5 * time.Second)
+	cfg.ShutdownTimeout = Duration(time.Second)
+	cfg.BaseMiddlewares = false
+	cfg.CatchAll = false
+	cfg.LogFormat = ""
+	cfg.Health = true
+	cfg.CORSOrigins = []string{"https://example.com"}
+	cfg.LimiterMax = 10
+
+	s := NewServerFromConfig(cfg)
+
+	assert.Equal(t, "from_config", s.app.Config().AppName)
+	assert.Equal(t, 5*time.Second, s.app.Config().ReadTimeout)
+	assert.Equal(t, 8080, s.port)
+	assert.Equal(t, time.Second, s.shutdownTimeout)
+	assert.True(t, s.methodAndPathExists("GET", "/health/ready"))
+	// CORS, limiter and HEAD/GET for the three health endpoints
+	assert.Equal(t, uint32(8), s.app.HandlersCount())
+}
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
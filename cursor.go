@@ -0,0 +1,325 @@
+package napi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/netr/napi/resp"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidCursor is returned for cursor tokens that are malformed, were signed with another secret or do not match the sort columns.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// DefaultCursorSigner signs cursor tokens when CursorOptions.Signer is not set. Set by WithCursorSecret and Config.CursorSecret.
+// When nil, tokens are signed with a random secret and a warning is logged: meant for development only, as the tokens do not survive restarts nor work across instances.
+var DefaultCursorSigner *CursorSigner
+
+var (
+	devCursorSigner     *CursorSigner
+	devCursorSignerOnce sync.Once
+)
+
+// CanCursorPaginate keyset pagination request. Cursor is an opaque token taken from the next_cursor or prev_cursor of the previous response; empty for the first page.
+type CanCursorPaginate struct {
+	Cursor string `query:"cursor"`
+	Limit  int    `query:"limit"`
+}
+
+// ValidateCursorPagination sets the default limit when it is missing or above 100.
+func (req *CanCursorPaginate) ValidateCursorPagination(limit int) {
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = limit
+	}
+}
+
+// KeysetColumn a column of the keyset ordering. The last column of the ordering must be unique, e.g. the primary key, so rows never tie.
+type KeysetColumn struct {
+	Name string
+	Desc bool
+}
+
+// CursorOptions configures CursorPaginate and CursorScope.
+type CursorOptions struct {
+	// Columns the ordering of the keyset, e.g. {{Name: "created_at", Desc: true}, {Name: "id", Desc: true}}. Defaults to id ascending. Columns must not be NULL.
+	Columns []KeysetColumn
+	// Signer signs and verifies the cursor tokens. Defaults to DefaultCursorSigner.
+	Signer *CursorSigner
+}
+
+// columns returns the configured ordering or id ascending.
+func (opts CursorOptions) columns() []KeysetColumn {
+	if len(opts.Columns) == 0 {
+		return []KeysetColumn{{Name: "id"}}
+	}
+	return opts.Columns
+}
+
+// signer returns the configured signer, DefaultCursorSigner, or the development signer when neither is set.
+func (opts CursorOptions) signer() *CursorSigner {
+	if opts.Signer != nil {
+		return opts.Signer
+	}
+	if DefaultCursorSigner != nil {
+		return DefaultCursorSigner
+	}
+
+	devCursorSignerOnce.Do(func() {
+		log.Println("napi: no cursor secret set, cursors are signed with a random secret. Set one with WithCursorSecret or Config.CursorSecret outside of development.")
+		devCursorSigner = NewCursorSigner(randomCursorSecret())
+	})
+	return devCursorSigner
+}
+
+// Cursor the decoded content of a cursor token: the sort key values of a row and the direction to read from it.
+type Cursor struct {
+	Values   []interface{}
+	Backward bool
+}
+
+// CursorSigner encodes cursors into opaque tokens signed with HMAC-SHA256, so clients cannot forge sort key values.
+type CursorSigner struct {
+	secret []byte
+}
+
+// NewCursorSigner creates a CursorSigner with the given secret.
+func NewCursorSigner(secret []byte) *CursorSigner {
+	return &CursorSigner{secret: secret}
+}
+
+// cursorPayload is the signed JSON part of a token. Times are tagged to keep their type through the round trip.
+type cursorPayload struct {
+	Backward bool          `json:"b,omitempty"`
+	Values   []cursorValue `json:"k"`
+}
+
+type cursorValue struct {
+	Time  *time.Time  `json:"t,omitempty"`
+	Value interface{} `json:"v,omitempty"`
+}
+
+// Encode creates the token of a cursor.
+func (s *CursorSigner) Encode(cursor Cursor) (string, error) {
+	payload := cursorPayload{Backward: cursor.Backward, Values: make([]cursorValue, len(cursor.Values))}
+	for i, v := range cursor.Values {
+		if t, ok := v.(time.Time); ok {
+			payload.Values[i] = cursorValue{Time: &t}
+		} else {
+			payload.Values[i] = cursorValue{Value: v}
+		}
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(b) + "." + enc.EncodeToString(s.sign(b)), nil
+}
+
+// Decode verifies a token and returns its cursor. Returns ErrInvalidCursor if the token was tampered with.
+func (s *CursorSigner) Decode(token string) (Cursor, error) {
+	enc := base64.RawURLEncoding
+	data, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+	b, err := enc.DecodeString(data)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	mac, err := enc.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, s.sign(b)) {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var payload cursorPayload
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err = dec.Decode(&payload); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	cursor := Cursor{Backward: payload.Backward, Values: make([]interface{}, len(payload.Values))}
+	for i, v := range payload.Values {
+		switch {
+		case v.Time != nil:
+			cursor.Values[i] = *v.Time
+		default:
+			cursor.Values[i] = fromJSONNumber(v.Value)
+		}
+	}
+	return cursor, nil
+}
+
+func (s *CursorSigner) sign(b []byte) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(b)
+	return mac.Sum(nil)
+}
+
+// CursorScope returns a gorm scope applying the keyset condition, ordering and limit of the request. Reading backward reverses the ordering, so the rows must be reversed again; CursorPaginate does this for you.
+func (req *CanCursorPaginate) CursorScope(opts CursorOptions) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		cursor, err := req.decodeCursor(opts)
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		return db.Scopes(keysetScope(opts.columns(), cursor, req.Limit))
+	}
+}
+
+// CursorPaginate runs a keyset page query and builds the resp.CursorMeta holding the tokens of the next and previous pages.
+func CursorPaginate[T any](db *gorm.DB, req *CanCursorPaginate, opts CursorOptions) ([]T, resp.CursorMeta, error) {
+	meta := resp.CursorMeta{Limit: req.Limit}
+	columns := opts.columns()
+
+	cursor, err := req.decodeCursor(opts)
+	if err != nil {
+		return nil, meta, err
+	}
+
+	items := make([]T, 0)
+	limit := req.Limit
+	if limit > 0 {
+		// fetch one more row to know if there is another page
+		limit++
+	}
+	if tx := db.Model(new(T)).Scopes(keysetScope(columns, cursor, limit)).Find(&items); tx.Error != nil {
+		return nil, meta, tx.Error
+	}
+
+	hasMore := req.Limit > 0 && len(items) > req.Limit
+	if hasMore {
+		items = items[:req.Limit]
+	}
+	if cursor.Backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	if len(items) == 0 {
+		return items, meta, nil
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err = stmt.Parse(new(T)); err != nil {
+		return nil, meta, err
+	}
+
+	hasNext, hasPrev := hasMore, req.Cursor != ""
+	if cursor.Backward {
+		hasNext, hasPrev = true, hasMore
+	}
+	if hasNext {
+		if meta.NextCursor, err = encodeRowCursor(stmt, columns, opts.signer(), items[len(items)-1], false); err != nil {
+			return nil, meta, err
+		}
+	}
+	if hasPrev {
+		if meta.PrevCursor, err = encodeRowCursor(stmt, columns, opts.signer(), items[0], true); err != nil {
+			return nil, meta, err
+		}
+	}
+
+	return items, meta, nil
+}
+
+// decodeCursor decodes the request's token. An empty token is the first page.
+func (req *CanCursorPaginate) decodeCursor(opts CursorOptions) (Cursor, error) {
+	if req.Cursor == "" {
+		return Cursor{}, nil
+	}
+
+	cursor, err := opts.signer().Decode(req.Cursor)
+	if err != nil {
+		return cursor, err
+	}
+	if len(cursor.Values) != len(opts.columns()) {
+		return cursor, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// keysetScope orders by the columns and keeps the rows after the cursor, e.g. `(a > ?) OR (a = ? AND b > ?)`. The expanded form supports mixed directions and every dialect.
+func keysetScope(columns []KeysetColumn, cursor Cursor, limit int) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(cursor.Values) > 0 {
+			ors := make([]clause.Expression, len(columns))
+			for i, col := range columns {
+				ands := make([]clause.Expression, 0, i+1)
+				for j := 0; j < i; j++ {
+					ands = append(ands, clause.Eq{Column: clause.Column{Name: columns[j].Name}, Value: cursor.Values[j]})
+				}
+
+				column := clause.Column{Name: col.Name}
+				if col.Desc != cursor.Backward {
+					ands = append(ands, clause.Lt{Column: column, Value: cursor.Values[i]})
+				} else {
+					ands = append(ands, clause.Gt{Column: column, Value: cursor.Values[i]})
+				}
+				ors[i] = clause.And(ands...)
+			}
+			db = db.Where(clause.Or(ors...))
+		}
+
+		for _, col := range columns {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: col.Name}, Desc: col.Desc != cursor.Backward})
+		}
+		if limit > 0 {
+			db = db.Limit(limit)
+		}
+		return db
+	}
+}
+
+// encodeRowCursor reads the sort key values of a row and encodes them into a token.
+func encodeRowCursor(stmt *gorm.Statement, columns []KeysetColumn, signer *CursorSigner, row interface{}, backward bool) (string, error) {
+	rv := reflect.Indirect(reflect.ValueOf(row))
+	cursor := Cursor{Backward: backward, Values: make([]interface{}, len(columns))}
+	for i, col := range columns {
+		field := stmt.Schema.LookUpField(col.Name)
+		if field == nil {
+			return "", fmt.Errorf("cursor: unknown column %s for %s", col.Name, stmt.Schema.Name)
+		}
+		cursor.Values[i], _ = field.ValueOf(context.Background(), rv)
+	}
+	return signer.Encode(cursor)
+}
+
+// fromJSONNumber converts decoded json numbers back into int64 when possible, float64 otherwise.
+func fromJSONNumber(v interface{}) interface{} {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	f, _ := n.Float64()
+	return f
+}
+
+// randomCursorSecret generates the secret of the development signer.
+func randomCursorSecret() []byte {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return secret
+}
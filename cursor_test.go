@@ -0,0 +1,158 @@
+package napi
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testCursorModel struct {
+	ID        uint
+	Username  string
+	Age       int
+	CreatedAt time.Time
+}
+
+func testSeedCursorModels(t *testing.T) *gorm.DB {
+	db := testGormDB(t, &testCursorModel{})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	models := []testCursorModel{
+		{Username: "a", Age: 30, CreatedAt: start.Add(1 * time.Hour)},
+		{Username: "b", Age: 20, CreatedAt: start.Add(2 * time.Hour)},
+		{Username: "c", Age: 30, CreatedAt: start.Add(3 * time.Hour)},
+		{Username: "d", Age: 40, CreatedAt: start.Add(4 * time.Hour)},
+		{Username: "e", Age: 20, CreatedAt: start.Add(5 * time.Hour)},
+	}
+	if err := db.Create(&models).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// testCursorPages reads every page forward and returns the usernames of each page.
+func testCursorPages(t *testing.T, db *gorm.DB, opts CursorOptions, limit int) [][]string {
+	var pages [][]string
+	req := &CanCursorPaginate{Limit: limit}
+	for i := 0; i < 10; i++ {
+		items, meta, err := CursorPaginate[testCursorModel](db, req, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, testCursorUsernames(items))
+		if meta.NextCursor == "" {
+			return pages
+		}
+		req.Cursor = meta.NextCursor
+	}
+	t.Fatal("too many pages")
+	return nil
+}
+
+func TestCursorPaginate_SingleColumn(t *testing.T) {
+	db := testSeedCursorModels(t)
+
+	pages := testCursorPages(t, db, CursorOptions{}, 2)
+	assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, pages)
+}
+
+func TestCursorPaginate_MultiColumnWithMixedDirections(t *testing.T) {
+	db := testSeedCursorModels(t)
+	opts := CursorOptions{Columns: []KeysetColumn{{Name: "age"}, {Name: "id", Desc: true}}}
+
+	pages := testCursorPages(t, db, opts, 2)
+	assert.Equal(t, [][]string{{"e", "b"}, {"c", "a"}, {"d"}}, pages)
+}
+
+func TestCursorPaginate_TimeColumn(t *testing.T) {
+	db := testSeedCursorModels(t)
+	opts := CursorOptions{Columns: []KeysetColumn{{Name: "created_at", Desc: true}, {Name: "id", Desc: true}}}
+
+	pages := testCursorPages(t, db, opts, 3)
+	assert.Equal(t, [][]string{{"e", "d", "c"}, {"b", "a"}}, pages)
+}
+
+func TestCursorPaginate_ShouldReadBackward(t *testing.T) {
+	db := testSeedCursorModels(t)
+	opts := CursorOptions{Columns: []KeysetColumn{{Name: "age"}, {Name: "id"}}}
+
+	req := &CanCursorPaginate{Limit: 2}
+	_, first, err := CursorPaginate[testCursorModel](db, req, opts)
+	assert.NoError(t, err)
+	assert.Empty(t, first.PrevCursor)
+
+	req.Cursor = first.NextCursor
+	items, second, err := CursorPaginate[testCursorModel](db, req, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, testCursorUsernames(items))
+
+	req.Cursor = second.PrevCursor
+	items, back, err := CursorPaginate[testCursorModel](db, req, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "e"}, testCursorUsernames(items))
+	assert.Empty(t, back.PrevCursor)
+	assert.NotEmpty(t, back.NextCursor)
+}
+
+func TestCursorPaginate_ShouldRejectTamperedCursor(t *testing.T) {
+	db := testSeedCursorModels(t)
+
+	token, err := CursorOptions{}.signer().Encode(Cursor{Values: []interface{}{2}})
+	assert.NoError(t, err)
+	data, _, _ := strings.Cut(token, ".")
+
+	forged, err := NewCursorSigner([]byte("other secret")).Encode(Cursor{Values: []interface{}{2}})
+	assert.NoError(t, err)
+	_, forgedSig, _ := strings.Cut(forged, ".")
+
+	for _, cursor := range []string{"garbage", data + "." + forgedSig, token + "x"} {
+		_, _, err = CursorPaginate[testCursorModel](db, &CanCursorPaginate{Cursor: cursor, Limit: 2}, CursorOptions{})
+		assert.ErrorIs(t, err, ErrInvalidCursor, cursor)
+	}
+}
+
+func TestCanCursorPaginate_CursorScope_ShouldAddDecodeErrors(t *testing.T) {
+	db := testSeedCursorModels(t)
+	req := &CanCursorPaginate{Cursor: "garbage", Limit: 2}
+
+	var items []testCursorModel
+	err := db.Scopes(req.CursorScope(CursorOptions{})).Find(&items).Error
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestCursorSigner_ShouldRoundTripValues(t *testing.T) {
+	signer := NewCursorSigner([]byte("secret"))
+	at := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	token, err := signer.Encode(Cursor{Values: []interface{}{at, "name", int64(42), 1.5}, Backward: true})
+	assert.NoError(t, err)
+
+	cursor, err := signer.Decode(token)
+	assert.NoError(t, err)
+	assert.True(t, cursor.Backward)
+	assert.True(t, at.Equal(cursor.Values[0].(time.Time)))
+	assert.Equal(t, []interface{}{"name", int64(42), 1.5}, cursor.Values[1:])
+}
+
+func testCursorUsernames(items []testCursorModel) []string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Username
+	}
+	return names
+}
+
+func TestWithCursorSecret_ShouldSignWithTheSharedSecret(t *testing.T) {
+	previous := DefaultCursorSigner
+	t.Cleanup(func() { DefaultCursorSigner = previous })
+
+	NewServer(DefaultFiberConfig("cursor_secret_test"), WithCursorSecret([]byte("shared secret")))
+
+	token, err := CursorOptions{}.signer().Encode(Cursor{Values: []interface{}{2}})
+	assert.NoError(t, err)
+	cursor, err := NewCursorSigner([]byte("shared secret")).Decode(token)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{int64(2)}, cursor.Values)
+}
@@ -0,0 +1,112 @@
+package napi
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/netr/napi/resp"
+	"gorm.io/gorm"
+)
+
+// DefaultErrors is the ErrorRegistry used by ErrorHandler. Register your own sentinel errors and error types here.
+var DefaultErrors = NewErrorRegistry()
+
+// ErrorHandler a fiber.ErrorHandler that sends errors returned by handlers as resp.ErrorResponse JSON, using the status codes registered in DefaultErrors. Use DefaultErrors.Handler(true) in production to hide the messages of unknown errors.
+//
+//	fiber.Config{ErrorHandler: napi.ErrorHandler}
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	return DefaultErrors.handle(c, err, false)
+}
+
+// errorMapping resolves the status code of an error. Returns false when the error does not match.
+type errorMapping func(err error) (int, bool)
+
+// ErrorRegistry maps sentinel errors and error types to status codes.
+type ErrorRegistry struct {
+	lock     *sync.RWMutex
+	mappings []errorMapping
+}
+
+// NewErrorRegistry creates a new ErrorRegistry. *fiber.Error keeps its own code, gorm.ErrRecordNotFound maps to http.StatusNotFound and ErrInvalidCursor to http.StatusBadRequest.
+func NewErrorRegistry() *ErrorRegistry {
+	r := &ErrorRegistry{lock: new(sync.RWMutex)}
+	r.RegisterFunc(func(err error) (int, bool) {
+		var fe *fiber.Error
+		if errors.As(err, &fe) {
+			return fe.Code, true
+		}
+		return 0, false
+	})
+	r.Register(gorm.ErrRecordNotFound, http.StatusNotFound)
+	r.Register(ErrInvalidCursor, http.StatusBadRequest)
+	return r
+}
+
+// Register maps a sentinel error, matched with errors.Is, to a status code. Later registrations take precedence.
+func (r *ErrorRegistry) Register(target error, code int) *ErrorRegistry {
+	return r.RegisterFunc(func(err error) (int, bool) {
+		return code, errors.Is(err, target)
+	})
+}
+
+// RegisterFunc adds a custom mapping that resolves the status code of an error. Later registrations take precedence.
+func (r *ErrorRegistry) RegisterFunc(fn func(err error) (int, bool)) *ErrorRegistry {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.mappings = append(r.mappings, fn)
+	return r
+}
+
+// RegisterErrorType maps an error type, matched with errors.As, to a status code. Later registrations take precedence.
+//
+//	napi.RegisterErrorType[*NotAllowedError](napi.DefaultErrors, http.StatusForbidden)
+func RegisterErrorType[T error](r *ErrorRegistry, code int) *ErrorRegistry {
+	return r.RegisterFunc(func(err error) (int, bool) {
+		var target T
+		return code, errors.As(err, &target)
+	})
+}
+
+// Status returns the status code registered for the error. Unknown errors are http.StatusInternalServerError.
+func (r *ErrorRegistry) Status(err error) int {
+	code, _ := r.lookup(err)
+	return code
+}
+
+// Handler returns a fiber.ErrorHandler backed by the registry. In production the messages of unknown errors are hidden.
+func (r *ErrorRegistry) Handler(production bool) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		return r.handle(c, err, production)
+	}
+}
+
+// lookup finds the status code of an error, newest mapping first.
+func (r *ErrorRegistry) lookup(err error) (int, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	for i := len(r.mappings) - 1; i >= 0; i-- {
+		if code, ok := r.mappings[i](err); ok {
+			return code, true
+		}
+	}
+	return http.StatusInternalServerError, false
+}
+
+// handle sends the error using the resp envelopes. Validation errors are sent as a resp.FormErrorResponse.
+func (r *ErrorRegistry) handle(c *fiber.Ctx, err error, production bool) error {
+	var vf *ValidationFailedError
+	if errors.As(err, &vf) {
+		return resp.New(c).FormError("validation failed", vf.Validation.Error())
+	}
+
+	code, known := r.lookup(err)
+	if !known && production {
+		err = errors.New(http.StatusText(code))
+	}
+
+	return resp.New(c).ErrorWithStatus(http.StatusText(code), err, code)
+}
@@ -0,0 +1,91 @@
+package napi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testForbiddenError struct{ reason string }
+
+func (e *testForbiddenError) Error() string { return e.reason }
+
+func TestErrorHandler_ShouldMapErrorsToStatusCodes(t *testing.T) {
+	errTeapot := errors.New("short and stout")
+	registry := NewErrorRegistry().Register(errTeapot, http.StatusTeapot)
+	RegisterErrorType[*testForbiddenError](registry, http.StatusForbidden)
+
+	tests := []struct {
+		name string
+		err  error
+		code int
+		body string
+	}{
+		{"gorm record not found is a 404", gorm.ErrRecordNotFound, http.StatusNotFound, `"error":"record not found"`},
+		{"wrapped sentinel errors are matched", fmt.Errorf("finding account: %w", gorm.ErrRecordNotFound), http.StatusNotFound, `"message":"Not Found"`},
+		{"fiber errors keep their code", fiber.NewError(http.StatusConflict, "already exists"), http.StatusConflict, `"error":"already exists"`},
+		{"registered sentinel errors", errTeapot, http.StatusTeapot, `"error":"short and stout"`},
+		{"registered error types", &testForbiddenError{"nope"}, http.StatusForbidden, `"error":"nope"`},
+		{"unknown errors are a 500", errors.New("boom"), http.StatusInternalServerError, `"error":"boom"`},
+		{"validation errors are form errors", (&ValidationError{bag: errorBag{"username": "Username is required"}}).Err(), http.StatusUnprocessableEntity, `"errors":{"username":"Username is required"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := testErrorHandlerRequest(t, registry.Handler(false), tt.err)
+
+			assert.Equal(t, tt.code, code)
+			assert.Contains(t, body, tt.body)
+		})
+	}
+}
+
+func TestErrorHandler_ShouldHideUnknownErrorsInProduction(t *testing.T) {
+	registry := NewErrorRegistry()
+
+	code, body := testErrorHandlerRequest(t, registry.Handler(true), errors.New("dial tcp 10.0.0.1: connection refused"))
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.NotContains(t, body, "connection refused")
+
+	code, body = testErrorHandlerRequest(t, registry.Handler(true), gorm.ErrRecordNotFound)
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Contains(t, body, "record not found")
+}
+
+func TestErrorRegistry_LaterRegistrationsTakePrecedence(t *testing.T) {
+	registry := NewErrorRegistry().Register(gorm.ErrRecordNotFound, http.StatusGone)
+
+	assert.Equal(t, http.StatusGone, registry.Status(gorm.ErrRecordNotFound))
+}
+
+func testErrorHandlerRequest(t *testing.T, handler fiber.ErrorHandler, err error) (int, string) {
+	app := fiber.New(fiber.Config{ErrorHandler: handler})
+	app.Get("/test", func(c *fiber.Ctx) error {
+		return err
+	})
+
+	res, reqErr := app.Test(httptest.NewRequest("GET", "/test", nil), 15000)
+	if reqErr != nil {
+		t.Fatal(reqErr)
+	}
+	body, reqErr := io.ReadAll(res.Body)
+	if reqErr != nil {
+		t.Fatal(reqErr)
+	}
+	return res.StatusCode, string(body)
+}
+
+func TestValidationError_Err(t *testing.T) {
+	var none *ValidationError
+	assert.NoError(t, none.Err())
+
+	ve := &ValidationError{bag: errorBag{"username": "Username is required", "age": "Age is too low"}}
+	assert.Equal(t, map[string]string{"username": "Username is required", "age": "Age is too low"}, ve.Error())
+	assert.EqualError(t, ve.Err(), "age: Age is too low; username: Username is required")
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/netr/napi/migrate"
+	"gorm.io/gorm"
+)
+
+// All lists every migration of the app, oldest first. Never edit an applied migration, add a new one instead.
+var All = []migrate.Migration{
+	{
+		ID: "20221201_create_accounts",
+		Up: func(tx *gorm.DB) error {
+			// the schema at the time of the migration, so later model changes don't alter it
+			type account struct {
+				ID        uint   `gorm:"primarykey"`
+				Username  string `gorm:"type: varchar(16); unique;"`
+				Password  string `gorm:"type: varchar(32);"`
+				CreatedAt time.Time
+				UpdatedAt time.Time
+			}
+			return tx.Table("accounts").Migrator().CreateTable(&account{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("accounts")
+		},
+	},
+}
@@ -6,7 +6,8 @@ import (
 )
 
 type FactorySuite struct {
-	factory *factory.Factory
+	factory  *factory.Factory
+	accounts *factory.Definition[Account]
 }
 
 func (suite *FactorySuite) Factory() *factory.Factory {
@@ -15,8 +16,14 @@ func (suite *FactorySuite) Factory() *factory.Factory {
 
 func (suite *FactorySuite) NewFactorySuite(db *gorm.DB) {
 	suite.factory = factory.New(db).Add(&Account{})
+	suite.accounts = factory.Define[Account](db, nil)
+}
+
+// Accounts is the typed account factory, e.g. suite.Accounts().Count(3).Create()
+func (suite *FactorySuite) Accounts() *factory.Definition[Account] {
+	return suite.accounts
 }
 
 func (suite *FactorySuite) CreateAccount() *Account {
-	return suite.Factory().Create(Account{}).(*Account)
+	return suite.Accounts().Create()
 }
@@ -7,22 +7,17 @@ import (
 )
 
 type AccountRepo struct {
-	db napi.IRepository[*gorm.DB]
+	db napi.Repository[models.Account]
 }
 
 func NewAccountRepo(db *gorm.DB) *AccountRepo {
 	return &AccountRepo{
-		db: napi.NewGormRepository(db),
+		db: napi.NewRepository[models.Account](napi.NewGormRepository(db)),
 	}
 }
 
 func (a *AccountRepo) GetAll() ([]models.Account, error) {
-	var accounts []models.Account
-	if tx := a.db.DB().Model(&models.Account{}).Find(&accounts); tx.Error != nil {
-		return nil, tx.Error
-	}
-
-	return accounts, nil
+	return a.db.FindBy()
 }
 
 func (a *AccountRepo) Create(acc *models.Account) (*models.Account, error) {
@@ -34,10 +29,5 @@ func (a *AccountRepo) Create(acc *models.Account) (*models.Account, error) {
 }
 
 func (a *AccountRepo) Update(id interface{}, password string) (*models.Account, error) {
-	model := new(models.Account)
-	if err := a.db.Update(model, id, napi.UpdateMap{"password": password}); err != nil {
-		return nil, err
-	}
-
-	return model, nil
+	return a.db.Update(id, napi.UpdateMap{"password": password})
 }
@@ -0,0 +1,49 @@
+package seeders
+
+import (
+	"github.com/netr/napi/examples/app/db/models"
+	"github.com/netr/napi/seed"
+)
+
+// All lists the seeders run by "app seed".
+var All = []seed.Seeder{
+	AdminSeeder{},
+	AccountSeeder{Count: 10},
+}
+
+// AdminSeeder creates the admin account once per database, outside of production since its password is public.
+type AdminSeeder struct{}
+
+func (AdminSeeder) Run(ctx *seed.Context) error {
+	return ctx.DB.Create(&models.Account{Username: "admin", Password: "changemeplease"}).Error
+}
+
+func (AdminSeeder) Idempotent() bool {
+	return true
+}
+
+func (AdminSeeder) Environments() []string {
+	return []string{"dev", "test", "demo", "staging"}
+}
+
+// AccountSeeder creates fake accounts, outside of production.
+type AccountSeeder struct {
+	Count int
+}
+
+func (s AccountSeeder) Run(ctx *seed.Context) error {
+	for i := 0; i < s.Count; i++ {
+		if _, err := ctx.Factory.CreateE(models.Account{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (AccountSeeder) Dependencies() []seed.Seeder {
+	return []seed.Seeder{AdminSeeder{}}
+}
+
+func (AccountSeeder) Environments() []string {
+	return []string{"dev", "test", "demo", "staging"}
+}
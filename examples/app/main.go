@@ -1,12 +1,21 @@
 package main
 
 import (
+	"context"
 	"github.com/netr/napi"
+	"github.com/netr/napi/examples/app/db/migrations"
+	"github.com/netr/napi/examples/app/db/models"
+	"github.com/netr/napi/examples/app/db/seeders"
 	"github.com/netr/napi/examples/app/web/ctrl"
+	"github.com/netr/napi/factory"
+	"github.com/netr/napi/health"
+	"github.com/netr/napi/migrate"
+	"github.com/netr/napi/seed"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	glog "gorm.io/gorm/logger"
 	"log"
+	"os"
 )
 
 var (
@@ -15,17 +24,29 @@ var (
 
 func main() {
 	var err error
+	db, err = newGormDB()
+	handleErr(err)
+
+	// go run . seed -env staging -rand-seed 42
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		f := factory.New(db).Add(&models.Account{})
+		handleErr(seed.Command(db, f, seeders.All, os.Args[2:], os.Stdout))
+		return
+	}
+
+	cfg := napi.DefaultFiberConfig("test_app")
+	cfg.ErrorHandler = napi.ErrorHandler
+
 	s := napi.NewServer(
-		napi.DefaultFiberConfig("test_app"),
+		cfg,
 		napi.WithCatchAll(),
+		napi.WithOnShutdown(closeGormDB),
+		napi.WithHealthCheck("db", health.Gorm(db)),
 	).
 		Port(1338).UseBaseMiddlewares().
 		UsePrometheus().UsePprof().UseHealth().
 		UseDefaultCORS().UseDefaultLogger().UseDefaultLimiter()
 
-	db, err = newGormDB()
-	handleErr(err)
-
 	ctrl.NewRoutes(s.App()).Setup(db)
 
 	s.Run()
@@ -46,11 +67,17 @@ func newGormDB() (*gorm.DB, error) {
 	if err != nil {
 		return nil, err
 	}
-	migrateDB(gormDb)
+	if err = migrate.New(gormDb, migrations.All).Migrate(); err != nil {
+		return nil, err
+	}
 
 	return gormDb, nil
 }
 
-func migrateDB(db *gorm.DB, migrations ...interface{}) {
-	_ = db.AutoMigrate(migrations...)
+func closeGormDB(_ context.Context) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
 }
@@ -2,8 +2,10 @@ package ctrl
 
 import (
 	"github.com/netr/napi/examples/app/db/models"
+	"github.com/netr/napi/examples/app/db/seeders"
 	"github.com/netr/napi/trex"
 	"github.com/stretchr/testify/suite"
+	"net/http"
 	"testing"
 )
 
@@ -23,6 +25,16 @@ func (s *accountSuite) TestIndex_ExpectedBehavior() {
 		AssertJsonEqual("data[1].username", b.Username)
 }
 
+func (s *accountSuite) TestIndex_ShouldListSeededAccounts() {
+	s.Seed(seeders.AccountSeeder{Count: 2})
+
+	trex.New(s).
+		Get(s.Route("accounts.index"), nil).
+		AssertOk().
+		AssertDataCount(3).
+		AssertJsonEqual("data[0].username", "admin")
+}
+
 func (s *accountSuite) TestStore_ExpectedBehavior() {
 	pd := s.MakeUrlValues("username=testinghere&password=doingthisheresedsd")
 	trex.New(s).
@@ -48,6 +60,16 @@ func (s *accountSuite) TestStore_ShouldFail_UsernameTooShort() {
 		AssertValidationErrors("username")
 }
 
+func (s *accountSuite) TestStore_ShouldFail_UsernameTaken() {
+	acc := s.CreateAccount()
+	pd := s.MakeUrlValues("username=" + acc.Username + "&password=doingthisheresedsd")
+	trex.New(s).
+		Post(s.Route("accounts.store"), &pd, nil).
+		AssertStatus(http.StatusInternalServerError).
+		AssertJsonEqual("message", "Internal Server Error").
+		AssertJsonPresent("error")
+}
+
 func (s *accountSuite) TestUpdate_ExpectedBehavior() {
 	acc := s.CreateAccount()
 	pd := s.MakeUrlValues("password=doingthisheresedsd")
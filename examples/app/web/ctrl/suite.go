@@ -1,7 +1,11 @@
 package ctrl
 
 import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/netr/napi"
+	"github.com/netr/napi/examples/app/db/migrations"
 	"github.com/netr/napi/examples/app/db/models"
+	"github.com/netr/napi/factory"
 	"github.com/netr/napi/sweets"
 )
 
@@ -13,18 +17,28 @@ type ControllerSuite struct {
 }
 
 func (suite *ControllerSuite) SetupSuite() {
-	suite.NewFiberSuite("/")
-	suite.NewGormSuite(&models.Account{})
+	// rerun a failing suite with FACTORY_SEED=<seed> to get the same fake data
+	suite.T().Logf("factory seed: %d", factory.RandomSeed())
+
+	suite.NewFiberSuite("/", fiber.Config{ErrorHandler: napi.ErrorHandler})
+	suite.NewGormSuiteWithMigrations(migrations.All...)
+	suite.UseTransactions()
 	suite.NewFactorySuite(suite.DB())
+	suite.UseFactory(suite.Factory())
 
 	NewRoutes(suite.App()).Setup(suite.DB())
 }
 
-// SetupTest will automatically refresh databases on every test. This can be overwritten in your test files.
+// SetupTest will automatically begin a transaction on every test. This can be overwritten in your test files.
 func (suite *ControllerSuite) SetupTest() {
 	suite.RefreshDB()
 }
 
+// TearDownTest will roll back the test's transaction, leaving the database empty for the next test. This can be overwritten in your test files.
+func (suite *ControllerSuite) TearDownTest() {
+	suite.RollbackDB()
+}
+
 // TearDownSuite will close the underlying sqlite connection when the test suite is finished. This can be overwritten in your test files.
 func (suite *ControllerSuite) TearDownSuite() {
 	_ = suite.App().Shutdown()
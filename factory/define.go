@@ -0,0 +1,285 @@
+package factory
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SeedEnv is the environment variable read by RandomSeed, e.g. FACTORY_SEED=42 go test ./...
+const SeedEnv = "FACTORY_SEED"
+
+// Definition is a typed factory of T. Its methods return copies, so a Definition can be shared and specialized freely.
+//
+//	accounts := factory.Define(db, func() *Account {
+//		return &Account{Username: faker.Username(), Password: faker.Password()}
+//	}).
+//		WithState("admin", func(a *Account) { a.Role = "admin" }).
+//		Sequence(func(i int, a *Account) { a.Email = fmt.Sprintf("user%d@example.com", i) })
+//
+//	admin := accounts.State("admin").Create()
+//	users := accounts.Count(3).Create()
+type Definition[T any] struct {
+	sql         *gorm.DB
+	maker       func() *T
+	states      map[string]state[T]
+	sequences   []func(i int, model *T)
+	applied     []func(*T)
+	afterMake   []func(model *T)
+	afterCreate []func(tx *gorm.DB, model *T) error
+	counter     *int64
+	fail        func(err error)
+}
+
+// state is a named state and its callbacks.
+type state[T any] struct {
+	apply       func(*T)
+	afterMake   []func(model *T)
+	afterCreate []func(tx *gorm.DB, model *T) error
+}
+
+// Define creates a typed factory of T. A nil maker uses T's IFactory implementation when it has one, or derives one from its struct tags otherwise.
+func Define[T any](sql *gorm.DB, maker func() *T) *Definition[T] {
+	if maker == nil {
+		maker = defaultMaker[T]()
+	}
+	return &Definition[T]{
+		sql:     sql,
+		maker:   maker,
+		states:  map[string]state[T]{},
+		counter: new(int64),
+		fail:    fatal,
+	}
+}
+
+// WithDB returns a copy of the definition creating models with db, e.g. a transaction.
+func (d *Definition[T]) WithDB(sql *gorm.DB) *Definition[T] {
+	cp := d.clone()
+	cp.sql = sql
+	return cp
+}
+
+// WithT returns a copy of the definition failing tb when Create fails, instead of exiting the test binary.
+func (d *Definition[T]) WithT(tb TB) *Definition[T] {
+	cp := d.clone()
+	cp.fail = func(err error) {
+		tb.Helper()
+		tb.Fatalf("factory: %v", err)
+	}
+	return cp
+}
+
+// WithState returns a copy of the definition with a named state applied by State.
+func (d *Definition[T]) WithState(name string, fn func(model *T)) *Definition[T] {
+	return d.withState(name, func(s *state[T]) { s.apply = fn })
+}
+
+// AfterMakeState returns a copy of the definition calling fn after making a model in the named state.
+func (d *Definition[T]) AfterMakeState(name string, fn func(model *T)) *Definition[T] {
+	return d.withState(name, func(s *state[T]) { s.afterMake = append(s.afterMake[:len(s.afterMake):len(s.afterMake)], fn) })
+}
+
+// AfterCreateState returns a copy of the definition calling fn in the create transaction of a model in the named state.
+func (d *Definition[T]) AfterCreateState(name string, fn func(tx *gorm.DB, model *T) error) *Definition[T] {
+	return d.withState(name, func(s *state[T]) { s.afterCreate = append(s.afterCreate[:len(s.afterCreate):len(s.afterCreate)], fn) })
+}
+
+// State returns a copy of the definition applying the named states and their callbacks, in order. Panics on unknown states.
+func (d *Definition[T]) State(names ...string) *Definition[T] {
+	cp := d.clone()
+	for _, name := range names {
+		s, ok := d.states[name]
+		if !ok {
+			panic(fmt.Sprintf("factory: unknown state %q of %T", name, *new(T)))
+		}
+		if s.apply != nil {
+			cp.applied = append(cp.applied, s.apply)
+		}
+		cp.afterMake = append(cp.afterMake, s.afterMake...)
+		cp.afterCreate = append(cp.afterCreate, s.afterCreate...)
+	}
+	return cp
+}
+
+// AfterMake returns a copy of the definition calling fn after making a model, after the states and overrides, e.g. to hash a password.
+func (d *Definition[T]) AfterMake(fn func(model *T)) *Definition[T] {
+	cp := d.clone()
+	cp.afterMake = append(cp.afterMake, fn)
+	return cp
+}
+
+// AfterCreate returns a copy of the definition calling fn after saving a model, in the same transaction, e.g. to attach roles.
+func (d *Definition[T]) AfterCreate(fn func(tx *gorm.DB, model *T) error) *Definition[T] {
+	cp := d.clone()
+	cp.afterCreate = append(cp.afterCreate, fn)
+	return cp
+}
+
+// Sequence returns a copy of the definition calling fn with an index incremented for every model made, starting at 1.
+// The index is shared by the copies of a definition, so sequenced values stay unique.
+func (d *Definition[T]) Sequence(fn func(i int, model *T)) *Definition[T] {
+	cp := d.clone()
+	cp.sequences = append(cp.sequences[:len(cp.sequences):len(cp.sequences)], fn)
+	return cp
+}
+
+// With returns a copy of the definition applying the overrides after the states.
+func (d *Definition[T]) With(overrides ...func(model *T)) *Definition[T] {
+	cp := d.clone()
+	cp.applied = append(cp.applied, overrides...)
+	return cp
+}
+
+// Make makes a new model without saving it to the database.
+func (d *Definition[T]) Make() *T {
+	model := d.maker()
+	i := int(atomic.AddInt64(d.counter, 1))
+	for _, fn := range d.sequences {
+		fn(i, model)
+	}
+	for _, fn := range d.applied {
+		fn(model)
+	}
+	for _, fn := range d.afterMake {
+		fn(model)
+	}
+	return model
+}
+
+// Create makes a new model and saves it to the database. Exits on errors, or fails the test when created with WithT.
+func (d *Definition[T]) Create() *T {
+	model, err := d.CreateE()
+	if err != nil {
+		d.fail(err)
+	}
+	return model
+}
+
+// CreateE makes a new model and saves it to the database with the AfterCreate callbacks, in a transaction.
+func (d *Definition[T]) CreateE() (*T, error) {
+	model := d.Make()
+	err := d.sql.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(model).Error; err != nil {
+			return err
+		}
+		for _, fn := range d.afterCreate {
+			if err := fn(tx, model); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// Count returns a builder of n models.
+func (d *Definition[T]) Count(n int) *Batch[T] {
+	return &Batch[T]{def: d, n: n}
+}
+
+// withState returns a copy of the definition with the named state updated by fn.
+func (d *Definition[T]) withState(name string, fn func(s *state[T])) *Definition[T] {
+	cp := d.clone()
+	cp.states = make(map[string]state[T], len(d.states)+1)
+	for k, v := range d.states {
+		cp.states[k] = v
+	}
+	s := cp.states[name]
+	fn(&s)
+	cp.states[name] = s
+	return cp
+}
+
+// clone copies the definition. Slices are capped so appending to a copy never writes to the original.
+func (d *Definition[T]) clone() *Definition[T] {
+	cp := *d
+	cp.applied = d.applied[:len(d.applied):len(d.applied)]
+	cp.afterMake = d.afterMake[:len(d.afterMake):len(d.afterMake)]
+	cp.afterCreate = d.afterCreate[:len(d.afterCreate):len(d.afterCreate)]
+	return &cp
+}
+
+// Batch makes or creates several models of a Definition.
+type Batch[T any] struct {
+	def *Definition[T]
+	n   int
+}
+
+// State returns a copy of the batch applying the named states to every model.
+func (b *Batch[T]) State(names ...string) *Batch[T] {
+	return &Batch[T]{def: b.def.State(names...), n: b.n}
+}
+
+// With returns a copy of the batch applying the overrides to every model.
+func (b *Batch[T]) With(overrides ...func(model *T)) *Batch[T] {
+	return &Batch[T]{def: b.def.With(overrides...), n: b.n}
+}
+
+// Make makes the models without saving them to the database.
+func (b *Batch[T]) Make() []*T {
+	models := make([]*T, b.n)
+	for i := range models {
+		models[i] = b.def.Make()
+	}
+	return models
+}
+
+// Create makes the models and saves them to the database. Exits on errors, or fails the test when created with WithT.
+func (b *Batch[T]) Create() []*T {
+	models, err := b.CreateE()
+	if err != nil {
+		b.def.fail(err)
+	}
+	return models
+}
+
+// CreateE makes the models and saves them to the database, stopping at the first error.
+func (b *Batch[T]) CreateE() ([]*T, error) {
+	models := make([]*T, b.n)
+	for i := range models {
+		model, err := b.def.CreateE()
+		if err != nil {
+			return nil, err
+		}
+		models[i] = model
+	}
+	return models, nil
+}
+
+// RandomSeed seeds faker with FACTORY_SEED when set, or with a random seed otherwise, and returns the seed.
+// Log it so a failing run can be reproduced with FACTORY_SEED=<seed>.
+func RandomSeed() int64 {
+	seed, err := strconv.ParseInt(os.Getenv(SeedEnv), 10, 64)
+	if err != nil {
+		seed = rand.New(rand.NewSource(time.Now().UnixNano())).Int63()
+	}
+	SetSeed(seed)
+	return seed
+}
+
+// fatal exits on errors, like the untyped Factory.
+func fatal(err error) {
+	log.Fatalln(err)
+}
+
+// defaultMaker returns T's IFactory implementation when it has one, or a maker derived from its struct tags.
+func defaultMaker[T any]() func() *T {
+	if f, ok := interface{}(new(T)).(IFactory); ok {
+		return func() *T {
+			if model, ok := f.Make().(*T); ok {
+				return model
+			}
+			return new(T)
+		}
+	}
+	return Derive[T]()
+}
@@ -0,0 +1,223 @@
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	glog "gorm.io/gorm/logger"
+)
+
+type testAccount struct {
+	ID       uint
+	Username string `gorm:"unique"`
+	Email    string
+	Role     string
+}
+
+func (a *testAccount) Make() interface{} {
+	return &testAccount{Username: faker.Username(), Role: "user"}
+}
+
+func testDB(t *testing.T, models ...interface{}) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: glog.Default.LogMode(glog.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	if err = db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func testAccounts(db *gorm.DB) *Definition[testAccount] {
+	return Define(db, func() *testAccount {
+		return &testAccount{Username: faker.Username(), Role: "user"}
+	}).
+		WithState("admin", func(a *testAccount) { a.Role = "admin" }).
+		WithState("banned", func(a *testAccount) { a.Role = "banned" }).
+		Sequence(func(i int, a *testAccount) { a.Email = fmt.Sprintf("user%d@example.com", i) })
+}
+
+func TestDefinition_Make(t *testing.T) {
+	accounts := testAccounts(nil)
+
+	a, b := accounts.Make(), accounts.Make()
+	assert.Equal(t, "user", a.Role)
+	assert.Equal(t, "user1@example.com", a.Email)
+	assert.Equal(t, "user2@example.com", b.Email)
+	assert.Zero(t, a.ID)
+}
+
+func TestDefinition_Create(t *testing.T) {
+	db := testDB(t, &testAccount{})
+
+	a := testAccounts(db).Create()
+	assert.Equal(t, uint(1), a.ID)
+
+	var count int64
+	db.Model(&testAccount{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestDefinition_State(t *testing.T) {
+	accounts := testAccounts(nil)
+
+	assert.Equal(t, "admin", accounts.State("admin").Make().Role)
+	assert.Equal(t, "banned", accounts.State("admin", "banned").Make().Role)
+	assert.Equal(t, "user", accounts.Make().Role)
+	assert.Panics(t, func() { accounts.State("missing") })
+}
+
+func TestDefinition_With(t *testing.T) {
+	a := testAccounts(nil).State("admin").With(func(a *testAccount) { a.Username = "root" }).Make()
+
+	assert.Equal(t, "root", a.Username)
+	assert.Equal(t, "admin", a.Role)
+}
+
+func TestDefinition_ShouldShareSequenceBetweenCopies(t *testing.T) {
+	accounts := testAccounts(nil)
+
+	assert.Equal(t, "user1@example.com", accounts.Make().Email)
+	assert.Equal(t, "user2@example.com", accounts.State("admin").Make().Email)
+}
+
+func TestDefinition_ShouldDefaultToIFactory(t *testing.T) {
+	a := Define[testAccount](nil, nil).Make()
+
+	assert.NotEmpty(t, a.Username)
+	assert.Equal(t, "user", a.Role)
+}
+
+func TestBatch_Create(t *testing.T) {
+	db := testDB(t, &testAccount{})
+
+	admins := testAccounts(db).Count(3).State("admin").Create()
+	assert.Len(t, admins, 3)
+	for i, a := range admins {
+		assert.Equal(t, uint(i+1), a.ID)
+		assert.Equal(t, "admin", a.Role)
+	}
+	assert.Len(t, testAccounts(db).Count(2).Make(), 2)
+}
+
+func TestRandomSeed_ShouldBeReproducible(t *testing.T) {
+	t.Setenv(SeedEnv, "42")
+
+	assert.Equal(t, int64(42), RandomSeed())
+	first := testAccounts(nil).Count(3).Make()
+
+	RandomSeed()
+	second := testAccounts(nil).Count(3).Make()
+	for i := range first {
+		assert.Equal(t, first[i].Username, second[i].Username)
+	}
+}
+
+var _ TB = (*testing.T)(nil)
+
+// testFakeTB records the failures instead of stopping the test.
+type testFakeTB struct {
+	failures []string
+}
+
+func (tb *testFakeTB) Helper() {}
+
+func (tb *testFakeTB) Fatalf(format string, args ...interface{}) {
+	tb.failures = append(tb.failures, fmt.Sprintf(format, args...))
+}
+
+func TestDefinition_CreateE(t *testing.T) {
+	db := testDB(t, &testAccount{})
+	accounts := testAccounts(db)
+
+	a, err := accounts.CreateE()
+	assert.NoError(t, err)
+	assert.NotZero(t, a.ID)
+
+	_, err = accounts.With(func(m *testAccount) { m.Username = a.Username }).CreateE()
+	assert.Error(t, err)
+
+	models, err := accounts.Count(2).CreateE()
+	assert.NoError(t, err)
+	assert.Len(t, models, 2)
+}
+
+func TestDefinition_WithT_ShouldFailTheTest(t *testing.T) {
+	db := testDB(t, &testAccount{})
+	tb := &testFakeTB{}
+	accounts := testAccounts(db).WithT(tb)
+
+	a := accounts.Create()
+	assert.Empty(t, tb.failures)
+
+	accounts.With(func(m *testAccount) { m.Username = a.Username }).Create()
+	assert.Len(t, tb.failures, 1)
+	assert.Contains(t, tb.failures[0], "UNIQUE constraint failed")
+}
+
+func TestDefinition_AfterMake(t *testing.T) {
+	var order []string
+	accounts := testAccounts(nil).
+		AfterMake(func(a *testAccount) { order = append(order, "after make "+a.Role) }).
+		AfterMakeState("admin", func(a *testAccount) { a.Username = "root" })
+
+	a := accounts.Make()
+	assert.NotEqual(t, "root", a.Username)
+
+	a = accounts.State("admin").Make()
+	assert.Equal(t, "root", a.Username)
+	assert.Equal(t, []string{"after make user", "after make admin"}, order)
+}
+
+func TestDefinition_AfterCreate(t *testing.T) {
+	db := testDB(t, &testAccount{}, &testProfile{})
+	accounts := testAccounts(db).
+		AfterCreateState("admin", func(tx *gorm.DB, a *testAccount) error {
+			return tx.Create(&testProfile{Bio: "admin", TestAuthorID: a.ID}).Error
+		})
+
+	accounts.Create()
+	a := accounts.State("admin").Create()
+
+	var profiles []testProfile
+	db.Find(&profiles)
+	assert.Len(t, profiles, 1)
+	assert.Equal(t, a.ID, profiles[0].TestAuthorID)
+
+	failing := accounts.AfterCreate(func(*gorm.DB, *testAccount) error { return errors.New("failed") })
+	_, err := failing.CreateE()
+	assert.Error(t, err)
+
+	var count int64
+	db.Model(&testAccount{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestFactory_CreateE(t *testing.T) {
+	db := testDB(t, &testAccount{})
+	f := New(db).Add(&testAccount{})
+
+	model, err := f.CreateE(testAccount{Role: "admin"})
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", model.(*testAccount).Role)
+
+	_, err = f.CreateE(testProfile{})
+	assert.Error(t, err)
+
+	tb := &testFakeTB{}
+	f.WithT(tb).Create(testAccount{Username: model.(*testAccount).Username})
+	assert.Len(t, tb.failures, 1)
+}
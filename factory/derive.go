@@ -0,0 +1,602 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bxcodec/faker/v3"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	lowerChars   = "abcdefghijklmnopqrstuvwxyz"
+	upperChars   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars   = "0123456789"
+	alphaChars   = lowerChars + upperChars
+	alnumChars   = alphaChars + digitChars
+	defaultChars = lowerChars + digitChars
+
+	// uniqueAttempts is the number of random values tried for a unique field before a counter is embedded.
+	uniqueAttempts = 10
+)
+
+// typeSizePattern matches the size of gorm types like varchar(16).
+var typeSizePattern = regexp.MustCompile(`\((\d+)\)`)
+
+// Derive builds a maker of T from struct tags, e.g. for Define. Every creatable column is filled, except primary keys, timestamps, associations and columns with a gorm default.
+//
+// Values come from the faker tag when there is one, or are guessed from the field name. They fit the gorm size of strings (size:16 or type:varchar(16)),
+// are unique among the models made by the maker for unique and uniqueIndex columns, and satisfy the validate tags of T and of rules, e.g. request DTOs, matched by field or json name.
+// Supported validate rules: required, min, max, len, eq, gt, gte, lt, lte, oneof, email, url, uuid, alpha, alphanum, numeric, lowercase, uppercase, startswith and password.
+//
+//	accounts := factory.Define(db, factory.Derive[Account](dto.AccountStoreRequest{}))
+func Derive[T any](rules ...interface{}) func() *T {
+	plan := newDerivePlan(reflect.TypeOf(new(T)).Elem(), rules)
+	return func() *T {
+		model := new(T)
+		plan.fill(reflect.ValueOf(model).Elem())
+		return model
+	}
+}
+
+// derivedFactory is the IFactory of a model added to a Factory without a Make method.
+type derivedFactory struct {
+	typ  reflect.Type
+	plan *derivePlan
+}
+
+// deriveFactory derives the IFactory of a model from its struct tags.
+func deriveFactory(model interface{}) IFactory {
+	typ := reflect.Indirect(reflect.ValueOf(model)).Type()
+	return &derivedFactory{typ: typ, plan: newDerivePlan(typ, nil)}
+}
+
+// Make makes a model with derived values.
+func (f *derivedFactory) Make() interface{} {
+	model := reflect.New(f.typ)
+	f.plan.fill(model.Elem())
+	return model.Interface()
+}
+
+// derivePlan is the parsed plan filling the fields of a model type.
+type derivePlan struct {
+	typ    reflect.Type
+	fields []*derivedField
+	faker  bool
+
+	lock sync.Mutex
+	seen map[string]map[string]bool
+	next map[string]int
+}
+
+// derivedField holds the constraints of a field.
+type derivedField struct {
+	field    *schema.Field
+	kind     reflect.Kind
+	isTime   bool
+	faker    bool
+	unique   bool
+	required bool
+
+	// lengths of strings, bounds of numbers
+	minLen, maxLen int
+	min, max       float64
+
+	oneOf    []string
+	format   string
+	chars    string
+	prefix   string
+	password bool
+}
+
+// newDerivePlan parses the fields and tags of a model type. Panics if it is not a gorm model.
+func newDerivePlan(typ reflect.Type, rules []interface{}) *derivePlan {
+	s, err := schema.Parse(reflect.New(typ).Interface(), schemas, schema.NamingStrategy{})
+	if err != nil {
+		panic(fmt.Errorf("factory: %w", err))
+	}
+
+	relations := map[string]bool{}
+	for _, rel := range s.Relationships.Relations {
+		relations[rel.Name] = true
+	}
+
+	plan := &derivePlan{typ: typ, seen: map[string]map[string]bool{}, next: map[string]int{}}
+	for _, f := range s.Fields {
+		if relations[f.Name] || !f.Creatable || f.DBName == "" || f.AutoCreateTime > 0 || f.AutoUpdateTime > 0 || f.StructField.Tag.Get("faker") == "-" {
+			continue
+		}
+
+		df := &derivedField{
+			field:  f,
+			kind:   f.IndirectFieldType.Kind(),
+			isTime: f.IndirectFieldType == reflect.TypeOf(time.Time{}),
+			faker:  f.StructField.Tag.Get("faker") != "",
+			unique: f.Unique || hasTagSetting(f, "UNIQUEINDEX") || f.PrimaryKey,
+			maxLen: -1,
+			min:    math.Inf(-1),
+			max:    math.Inf(1),
+			chars:  defaultChars,
+		}
+		if !df.isTime && !isDerivableKind(df.kind) {
+			continue
+		}
+		if f.PrimaryKey && df.kind != reflect.String {
+			continue
+		}
+
+		if f.Size > 0 && df.kind == reflect.String {
+			df.maxLen = f.Size
+		}
+		if m := typeSizePattern.FindStringSubmatch(f.TagSettings["TYPE"]); m != nil && df.kind == reflect.String {
+			df.maxLen, _ = strconv.Atoi(m[1])
+		}
+
+		df.applyRules(f.StructField.Tag.Get("validate"))
+		for _, rule := range rules {
+			df.applyRules(matchingValidateTag(rule, f))
+		}
+		if f.HasDefaultValue && !df.required {
+			continue
+		}
+
+		plan.faker = plan.faker || df.faker
+		plan.fields = append(plan.fields, df)
+	}
+	return plan
+}
+
+// applyRules narrows the constraints with the rules of a validate tag. Unknown rules are ignored.
+func (df *derivedField) applyRules(tag string) {
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		n, numErr := strconv.ParseFloat(param, 64)
+
+		switch name {
+		case "required":
+			df.required = true
+		case "min", "gte":
+			if numErr == nil {
+				df.atLeast(n)
+			}
+		case "max", "lte":
+			if numErr == nil {
+				df.atMost(n)
+			}
+		case "gt":
+			if numErr == nil {
+				df.atLeast(n + 1)
+			}
+		case "lt":
+			if numErr == nil {
+				df.atMost(n - 1)
+			}
+		case "len", "eq":
+			if numErr == nil && (name == "len" || df.kind != reflect.String) {
+				df.atLeast(n)
+				df.atMost(n)
+			}
+		case "oneof":
+			df.oneOf = strings.Fields(param)
+		case "email", "url", "uuid", "uuid4":
+			df.format = name
+		case "alpha":
+			df.chars = alphaChars
+		case "alphanum":
+			df.chars = alnumChars
+		case "numeric", "number":
+			df.chars = digitChars
+		case "lowercase":
+			df.chars = lowerChars + digitChars
+		case "uppercase":
+			df.chars = upperChars + digitChars
+		case "startswith":
+			df.prefix = param
+		case "password":
+			df.password = true
+		}
+	}
+}
+
+// atLeast raises the minimum length of strings or the minimum of numbers.
+func (df *derivedField) atLeast(n float64) {
+	if df.kind == reflect.String {
+		if int(n) > df.minLen {
+			df.minLen = int(n)
+		}
+	} else if n > df.min {
+		df.min = n
+	}
+}
+
+// atMost lowers the maximum length of strings or the maximum of numbers.
+func (df *derivedField) atMost(n float64) {
+	if df.kind == reflect.String {
+		if df.maxLen < 0 || int(n) < df.maxLen {
+			df.maxLen = int(n)
+		}
+	} else if n < df.max {
+		df.max = n
+	}
+}
+
+// fill sets the derived values on a model.
+func (p *derivePlan) fill(model reflect.Value) {
+	var faked reflect.Value
+	if p.faker {
+		faked = reflect.New(p.typ)
+		if err := faker.FakeData(faked.Interface()); err != nil {
+			faked = reflect.Value{}
+		}
+	}
+
+	ctx := context.Background()
+	for _, df := range p.fields {
+		var value interface{}
+		if df.faker && faked.IsValid() {
+			value = df.fit(reflect.Indirect(df.field.ReflectValueOf(ctx, faked.Elem())).Interface())
+		} else {
+			value = df.generate()
+		}
+
+		if df.unique {
+			value = p.unique(df, value)
+		}
+		if err := df.field.Set(ctx, model, value); err != nil {
+			panic(fmt.Errorf("factory: %s: %w", df.field.Name, err))
+		}
+	}
+}
+
+// unique regenerates the value until it was never made for the field, then embeds a counter.
+func (p *derivePlan) unique(df *derivedField, value interface{}) interface{} {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	seen := p.seen[df.field.Name]
+	if seen == nil {
+		seen = map[string]bool{}
+		p.seen[df.field.Name] = seen
+	}
+
+	for i := 0; seen[fmt.Sprint(value)] && i < uniqueAttempts; i++ {
+		value = df.generate()
+	}
+	// the counter is embedded in the last random value, until the result was never made or every integer of the range was tried
+	base, limit := value, df.counterLimit()
+	for i := 0; seen[fmt.Sprint(value)] && (limit < 0 || i < limit); i++ {
+		p.next[df.field.Name]++
+		next, ok := df.withCounter(base, p.next[df.field.Name])
+		if !ok {
+			// e.g. booleans, which cannot be unique
+			break
+		}
+		value = next
+	}
+	seen[fmt.Sprint(value)] = true
+	return value
+}
+
+// generate makes a value satisfying the constraints of the field.
+func (df *derivedField) generate() interface{} {
+	if len(df.oneOf) > 0 {
+		return df.oneOf[random.Intn(len(df.oneOf))]
+	}
+
+	switch {
+	case df.isTime:
+		return time.Now().Add(-time.Duration(random.Int63n(int64(365 * 24 * time.Hour)))).Truncate(time.Second)
+	case df.kind == reflect.Bool:
+		// false fails the required rule
+		return df.required || random.Intn(2) == 1
+	case df.kind == reflect.String:
+		return df.fit(df.guessString())
+	case df.kind == reflect.Float32 || df.kind == reflect.Float64:
+		min, max := df.bounds(0, 1000)
+		if max < min {
+			max = min
+		}
+		return min + random.Float64()*(max-min)
+	}
+
+	min, max := df.intBounds()
+	span := uint64(max) - uint64(min)
+	if span == math.MaxUint64 {
+		return int64(random.Uint64())
+	}
+	return min + int64(random.Uint64()%(span+1))
+}
+
+// intBounds returns the range of integers, within the range of the kind of the field so values never overflow it.
+func (df *derivedField) intBounds() (int64, int64) {
+	kindMin, kindMax := intKindRange(df.field.IndirectFieldType)
+	min, max := df.bounds(1, 1000)
+	lo, hi := clampInt64(math.Ceil(min), kindMin, kindMax), clampInt64(math.Floor(max), kindMin, kindMax)
+	if df.required && lo <= 0 && hi >= 1 {
+		// zero fails the required rule
+		lo = 1
+	}
+	if hi < lo {
+		hi = lo
+	}
+	return lo, hi
+}
+
+// bounds returns the range of numbers, defaulting to [min, max] on the unbounded sides.
+func (df *derivedField) bounds(min, max float64) (float64, float64) {
+	switch {
+	case !math.IsInf(df.min, 0) && !math.IsInf(df.max, 0):
+		return df.min, df.max
+	case !math.IsInf(df.min, 0):
+		return df.min, df.min + max - min
+	case !math.IsInf(df.max, 0):
+		return math.Min(min, df.max), df.max
+	}
+	return min, max
+}
+
+// guessString makes a string from the format or the name of the field.
+func (df *derivedField) guessString() string {
+	switch df.format {
+	case "email":
+		return faker.Email()
+	case "url":
+		return faker.URL()
+	case "uuid", "uuid4":
+		return faker.UUIDHyphenated()
+	}
+	if df.chars != defaultChars {
+		return ""
+	}
+
+	name := strings.ToLower(df.field.Name)
+	switch {
+	case strings.Contains(name, "email"):
+		return faker.Email()
+	case strings.Contains(name, "username"):
+		return faker.Username()
+	case strings.Contains(name, "password"):
+		return faker.Password()
+	case strings.Contains(name, "url"):
+		return faker.URL()
+	case strings.Contains(name, "phone"):
+		return faker.Phonenumber()
+	case strings.HasSuffix(name, "name"):
+		return faker.Name()
+	case strings.Contains(name, "title"):
+		return faker.Sentence()
+	case strings.Contains(name, "description"), strings.Contains(name, "body"), strings.Contains(name, "content"):
+		return faker.Paragraph()
+	}
+	return faker.Word()
+}
+
+// fit adjusts a string to the length, charset, prefix and password rules. Other values are returned as is.
+func (df *derivedField) fit(value interface{}) interface{} {
+	s, ok := value.(string)
+	if !ok || df.kind != reflect.String {
+		return value
+	}
+
+	if df.chars != defaultChars {
+		s = keepChars(s, df.chars)
+	}
+	if df.prefix != "" && !strings.HasPrefix(s, df.prefix) {
+		s = df.prefix + s
+	}
+
+	maxLen := df.maxLen
+	minLen := maxInt(df.minLen, 1)
+	if df.password {
+		minLen = maxInt(minLen, 2)
+	}
+	if maxLen >= 0 && minLen > maxLen {
+		minLen = maxLen
+	}
+
+	if maxLen >= 0 && len(s) > maxLen {
+		if df.format == "email" {
+			s = fitEmail(maxLen)
+		} else {
+			s = s[:maxLen]
+		}
+	}
+	for len(s) < minLen {
+		s += string(df.chars[random.Intn(len(df.chars))])
+	}
+
+	if df.password && len(s) >= 2 {
+		s = withPasswordChars(s)
+	}
+	return s
+}
+
+// withCounter makes a value unique by embedding a counter. Integers wrap around within their range. Returns false for values that cannot embed one.
+func (df *derivedField) withCounter(value interface{}, n int) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		suffix := encodeCounter(n, df.chars)
+		if df.format == "email" {
+			return df.emailWithCounter(v, suffix), true
+		}
+		if df.maxLen >= 0 && len(v)+len(suffix) > df.maxLen {
+			v = v[:maxInt(df.maxLen-len(suffix), len(df.prefix))]
+		}
+		return v + suffix, true
+	}
+
+	rv := reflect.ValueOf(value)
+	var i int64
+	switch {
+	case rv.CanInt():
+		i = rv.Int()
+	case rv.CanUint():
+		i = clampInt64(float64(rv.Uint()), 0, math.MaxInt64)
+	case rv.CanFloat():
+		return rv.Float() + float64(n), true
+	default:
+		return value, false
+	}
+
+	min, max := df.intBounds()
+	span := uint64(max) - uint64(min)
+	offset := uint64(i) - uint64(min) + uint64(n)
+	if span < math.MaxUint64 {
+		offset %= span + 1
+	}
+	return min + int64(offset), true
+}
+
+// emailWithCounter embeds the counter in the local part of an email, shortened to keep the address within the maximum length.
+func (df *derivedField) emailWithCounter(email, suffix string) string {
+	local, domain, _ := strings.Cut(email, "@")
+	if df.maxLen >= 0 && len(local)+len(suffix)+1+len(domain) > df.maxLen {
+		if len(suffix)+1+len(domain) > df.maxLen {
+			domain = "x.io"
+		}
+		local = local[:maxInt(minInt(df.maxLen-len(suffix)-1-len(domain), len(local)), 0)]
+	}
+	return local + suffix + "@" + domain
+}
+
+// counterLimit is the number of counters worth trying to make a unique value: every integer of the range, or -1 for no limit.
+func (df *derivedField) counterLimit() int {
+	if !isIntKind(df.kind) {
+		return -1
+	}
+	min, max := df.intBounds()
+	if span := uint64(max) - uint64(min); span < math.MaxInt32 {
+		return int(span) + 1
+	}
+	return -1
+}
+
+// fitEmail makes an email address of at most n characters.
+func fitEmail(n int) string {
+	domain := "@x.io"
+	local := ""
+	for len(local)+len(domain) < n {
+		local += string(lowerChars[random.Intn(len(lowerChars))])
+	}
+	return local + domain
+}
+
+// withPasswordChars makes sure a password has an uppercase letter and a number.
+func withPasswordChars(s string) string {
+	b := []byte(s)
+	if !strings.ContainsAny(s, upperChars) {
+		b[0] = upperChars[random.Intn(len(upperChars))]
+	}
+	if !strings.ContainsAny(string(b), digitChars) {
+		b[len(b)-1] = digitChars[random.Intn(len(digitChars))]
+	}
+	return string(b)
+}
+
+// keepChars removes the characters of s not in chars.
+func keepChars(s, chars string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(chars, r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// encodeCounter writes n with the characters of chars as digits.
+func encodeCounter(n int, chars string) string {
+	base := len(chars)
+	var b []byte
+	for ; n > 0; n /= base {
+		b = append([]byte{chars[n%base]}, b...)
+	}
+	return string(b)
+}
+
+// matchingValidateTag gets the validate tag of the field of rule matching f by name or json name.
+func matchingValidateTag(rule interface{}, f *schema.Field) string {
+	typ := reflect.Indirect(reflect.ValueOf(rule)).Type()
+	if sf, ok := typ.FieldByName(f.Name); ok {
+		return sf.Tag.Get("validate")
+	}
+
+	name := strings.Split(f.StructField.Tag.Get("json"), ",")[0]
+	if name == "" {
+		return ""
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if strings.Split(typ.Field(i).Tag.Get("json"), ",")[0] == name {
+			return typ.Field(i).Tag.Get("validate")
+		}
+	}
+	return ""
+}
+
+// hasTagSetting checks if the gorm tag of the field has the setting.
+func hasTagSetting(f *schema.Field, name string) bool {
+	_, ok := f.TagSettings[name]
+	return ok
+}
+
+// isDerivableKind checks if values of the kind can be derived.
+func isDerivableKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// isIntKind checks if the kind is a signed or unsigned integer.
+func isIntKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Uint64
+}
+
+// intKindRange returns the range of values of an integer type. Unsigned integers are capped to the range of int64.
+func intKindRange(typ reflect.Type) (int64, int64) {
+	bits := typ.Bits()
+	if typ.Kind() >= reflect.Uint && typ.Kind() <= reflect.Uint64 {
+		if bits == 64 {
+			return 0, math.MaxInt64
+		}
+		return 0, 1<<bits - 1
+	}
+	return -1 << (bits - 1), 1<<(bits-1) - 1
+}
+
+// clampInt64 converts f to an int64 within [min, max].
+func clampInt64(f float64, min, max int64) int64 {
+	switch {
+	case f <= float64(min):
+		return min
+	case f >= float64(max):
+		return max
+	}
+	return int64(f)
+}
+
+// maxInt returns the larger of a and b.
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// minInt returns the smaller of a and b.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
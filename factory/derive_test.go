@@ -0,0 +1,106 @@
+package factory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+type testMember struct {
+	ID        uint
+	Username  string `gorm:"type: varchar(16); unique"`
+	Email     string `gorm:"size:64" validate:"required,email"`
+	Password  string `gorm:"type: varchar(32)"`
+	Nickname  string `faker:"first_name"`
+	Role      string `validate:"oneof=user admin"`
+	Age       int    `validate:"gte=18,lte=99"`
+	Status    string `gorm:"default:active"`
+	CreatedAt time.Time
+}
+
+type testMemberRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=16,alphanum"`
+	Password string `json:"password" validate:"required,min=8,max=32"`
+}
+
+func TestDerive_ShouldSatisfyTags(t *testing.T) {
+	SetSeed(1)
+	members := Derive[testMember](testMemberRequest{})
+	validate := validator.New()
+
+	for i := 0; i < 50; i++ {
+		m := members()
+		assert.NoError(t, validate.Struct(m))
+		assert.NoError(t, validate.Struct(testMemberRequest{Username: m.Username, Password: m.Password}))
+		assert.LessOrEqual(t, len(m.Username), 16)
+		assert.NotEmpty(t, m.Nickname)
+		assert.Zero(t, m.ID)
+		assert.Empty(t, m.Status)
+		assert.True(t, m.CreatedAt.IsZero())
+	}
+}
+
+func TestDerive_ShouldCreateUniqueModels(t *testing.T) {
+	db := testDB(t, &testMember{})
+	members := Define(db, Derive[testMember](testMemberRequest{}))
+
+	created, err := members.Count(200).CreateE()
+	assert.NoError(t, err)
+	assert.Len(t, created, 200)
+}
+
+type testCounter struct {
+	ID    uint
+	Level uint8 `validate:"gte=200"`
+	Rank  int8
+	Code  uint8  `gorm:"unique"`
+	Email string `gorm:"size:12;unique" validate:"email"`
+}
+
+func TestDerive_ShouldStayWithinTheKindAndLength(t *testing.T) {
+	SetSeed(3)
+	counters := Derive[testCounter]()
+	validate := validator.New()
+
+	codes, emails := map[uint8]bool{}, map[string]bool{}
+	for i := 0; i < 255; i++ {
+		c := counters()
+		assert.GreaterOrEqual(t, c.Level, uint8(200))
+		assert.Greater(t, c.Rank, int8(0))
+		assert.LessOrEqual(t, len(c.Email), 12, c.Email)
+		assert.NoError(t, validate.Var(c.Email, "email"), c.Email)
+		codes[c.Code], emails[c.Email] = true, true
+	}
+	assert.Len(t, codes, 255)
+	assert.Len(t, emails, 255)
+}
+
+func TestDerive_ShouldBeReproducible(t *testing.T) {
+	SetSeed(7)
+	first := Derive[testMember]()()
+	SetSeed(7)
+	second := Derive[testMember]()()
+
+	assert.Equal(t, first, second)
+}
+
+func TestDefine_ShouldPreferMakeOverDerive(t *testing.T) {
+	a := Define[testAccount](nil, nil).Make()
+	assert.Equal(t, "user", a.Role)
+
+	m := Define[testMember](nil, nil).Make()
+	assert.NotEmpty(t, m.Username)
+	assert.Contains(t, []string{"user", "admin"}, m.Role)
+}
+
+func TestFactory_Add_ShouldDeriveWithoutMake(t *testing.T) {
+	db := testDB(t, &testMember{})
+	f := New(db).Add(&testMember{})
+
+	m := f.Create(testMember{Role: "admin"}).(*testMember)
+	assert.NotZero(t, m.ID)
+	assert.Equal(t, "admin", m.Role)
+	assert.NotEmpty(t, m.Username)
+}
@@ -1,14 +1,24 @@
 package factory
 
 import (
+	"fmt"
+	"github.com/bxcodec/faker/v3"
 	"gorm.io/gorm"
-	"log"
+	"math/rand"
 	"reflect"
+	"time"
 )
 
+// TB is the part of testing.TB used to fail a test, so the package does not import testing. Satisfied by *testing.T and *testing.B.
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+}
+
 type Factory struct {
 	factories factoryMap
 	sql       *gorm.DB
+	fail      func(err error)
 }
 
 // New instantiates a new factory struct with *gorm.DB
@@ -16,13 +26,49 @@ func New(sql *gorm.DB) *Factory {
 	return &Factory{
 		factories: factoryMap{},
 		sql:       sql,
+		fail:      fatal,
+	}
+}
+
+// WithDB returns a copy of the factory creating models with db, e.g. a transaction. Registered factories are shared.
+func (f *Factory) WithDB(db *gorm.DB) *Factory {
+	return &Factory{
+		factories: f.factories,
+		sql:       db,
+		fail:      f.fail,
+	}
+}
+
+// WithT returns a copy of the factory failing tb when Create fails, instead of exiting the test binary. Registered factories are shared.
+func (f *Factory) WithT(tb TB) *Factory {
+	return &Factory{
+		factories: f.factories,
+		sql:       f.sql,
+		fail: func(err error) {
+			tb.Helper()
+			tb.Fatalf("factory: %v", err)
+		},
 	}
 }
 
-// Add a new factory into the factoryMap.
+// random is the source of the values derived from struct tags, seeded along with faker by SetSeed.
+var random = rand.New(faker.NewSafeSource(rand.NewSource(time.Now().UnixNano())))
+
+// SetSeed seeds the random source of faker and of the derived factories, making the models generated afterwards reproducible.
+func SetSeed(seed int64) {
+	faker.SetRandomSource(faker.NewSafeSource(rand.NewSource(seed)))
+	faker.ResetUnique()
+	random = rand.New(faker.NewSafeSource(rand.NewSource(seed)))
+}
+
+// Add a new factory into the factoryMap. Models without a Make method get a factory derived from their struct tags.
 func (f *Factory) Add(model ...interface{}) *Factory {
 	for _, m := range model {
-		f.factories.set(m, m.(IFactory))
+		if fac, ok := m.(IFactory); ok {
+			f.factories.set(m, fac)
+		} else {
+			f.factories.set(m, deriveFactory(m))
+		}
 	}
 	return f
 }
@@ -36,13 +82,25 @@ func (f *Factory) Make(model interface{}) interface{} {
 	return nil
 }
 
-// Create will use the underlying gorm.DB and create a new model
+// Create will use the underlying gorm.DB and create a new model. Exits on errors, or fails the test when created with WithT.
 func (f *Factory) Create(model interface{}) interface{} {
-	model = f.Make(model)
-	if tx := f.sql.Create(model); tx.Error != nil {
-		log.Fatalln(tx.Error)
+	created, err := f.CreateE(model)
+	if err != nil {
+		f.fail(err)
+	}
+	return created
+}
+
+// CreateE will use the underlying gorm.DB and create a new model, returning the error instead of exiting
+func (f *Factory) CreateE(model interface{}) (interface{}, error) {
+	made := f.Make(model)
+	if made == nil {
+		return nil, fmt.Errorf("no factory added for %s", getModelType(model))
+	}
+	if tx := f.sql.Create(made); tx.Error != nil {
+		return nil, tx.Error
 	}
-	return model
+	return made, nil
 }
 
 type factoryMap map[string]IFactory
@@ -91,7 +149,9 @@ func overwriteStructFields(src interface{}, dest interface{}) interface{} {
 					fvModel.Set(reflect.ValueOf(fvOverride.Interface()))
 				}
 			} else if fvEmpty.Kind() == reflect.Slice && fvOverride.Kind() == reflect.Slice {
-
+				if fvOverride.Len() > 0 {
+					fvModel.Set(fvOverride)
+				}
 			} else {
 				if fvEmpty.Interface() != fvOverride.Interface() {
 					fvModel.Set(reflect.ValueOf(fvOverride.Interface()))
@@ -0,0 +1,153 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+// schemas caches the parsed gorm schemas of the models.
+var schemas = &sync.Map{}
+
+// Related is a factory of another model, used to build associations with For and Has. Implemented by every Definition.
+type Related interface {
+	makeRelated() reflect.Value
+	relatedType() reflect.Type
+}
+
+// makeRelated makes a model for an association, as a pointer.
+func (d *Definition[T]) makeRelated() reflect.Value {
+	return reflect.ValueOf(d.Make())
+}
+
+// relatedType is the type of the models made by the definition.
+func (d *Definition[T]) relatedType() reflect.Type {
+	return reflect.TypeOf(new(T)).Elem()
+}
+
+// For returns a copy of the definition assigning a parent to the belongs-to association of its type.
+// The parent is either a model, e.g. an already created *Account, or a Related factory making a new parent for every model.
+// Foreign keys are set from the parent's primary key when it has one, otherwise gorm creates the parent and wires them on Create.
+// An association name is needed when the model belongs to several parents of the same type. Panics when there is no matching association.
+//
+//	post := posts.For(account).Create()
+//	post := posts.For(accounts).Create() // creates a new account
+func (d *Definition[T]) For(parent interface{}, association ...string) *Definition[T] {
+	newParent := func() reflect.Value { return reflect.ValueOf(parent) }
+	typ := reflect.Indirect(reflect.ValueOf(parent)).Type()
+	if related, ok := parent.(Related); ok {
+		newParent, typ = related.makeRelated, related.relatedType()
+	}
+
+	rel := relationship[T](typ, association, schema.BelongsTo)
+	return d.With(func(model *T) {
+		setParent(rel, reflect.ValueOf(model).Elem(), newParent())
+	})
+}
+
+// Has returns a copy of the definition adding n children made by child to the has-one, has-many or many-to-many association of their type.
+// gorm creates the children and wires their foreign keys on Create.
+// An association name is needed when the model has several associations of the same type. Panics when there is no matching association.
+//
+//	account := accounts.Has(3, posts).Create()
+func (d *Definition[T]) Has(n int, child Related, association ...string) *Definition[T] {
+	rel := relationship[T](child.relatedType(), association, schema.HasOne, schema.HasMany, schema.Many2Many)
+	return d.With(func(model *T) {
+		children := make([]reflect.Value, n)
+		for i := range children {
+			children[i] = child.makeRelated()
+		}
+		setChildren(rel, reflect.ValueOf(model).Elem(), children)
+	})
+}
+
+// relationship finds the association of T to the related type.
+func relationship[T any](related reflect.Type, association []string, types ...schema.RelationshipType) *schema.Relationship {
+	s, err := schema.Parse(new(T), schemas, schema.NamingStrategy{})
+	if err != nil {
+		panic(fmt.Errorf("factory: %w", err))
+	}
+
+	var found []*schema.Relationship
+	for _, rel := range s.Relationships.Relations {
+		if rel.FieldSchema.ModelType != related || !containsRelationshipType(types, rel.Type) {
+			continue
+		}
+		if len(association) > 0 && rel.Name != association[0] {
+			continue
+		}
+		found = append(found, rel)
+	}
+
+	switch len(found) {
+	case 0:
+		panic(fmt.Sprintf("factory: %s has no %v association to %s", s.Name, types, related))
+	case 1:
+		return found[0]
+	}
+	panic(fmt.Sprintf("factory: %s has several associations to %s, pass the association name", s.Name, related))
+}
+
+// setParent assigns the parent to the association and copies its primary key to the foreign keys.
+func setParent(rel *schema.Relationship, model reflect.Value, parent reflect.Value) {
+	ctx := context.Background()
+	setRelationField(rel.Field, model, parent)
+
+	for _, ref := range rel.References {
+		if ref.OwnPrimaryKey || ref.PrimaryKey == nil {
+			continue
+		}
+		if pk, zero := ref.PrimaryKey.ValueOf(ctx, reflect.Indirect(parent)); !zero {
+			if err := ref.ForeignKey.Set(ctx, model, pk); err != nil {
+				panic(fmt.Errorf("factory: %w", err))
+			}
+		}
+	}
+}
+
+// setChildren appends the children to a slice association, or assigns the last one to a has-one association.
+func setChildren(rel *schema.Relationship, model reflect.Value, children []reflect.Value) {
+	if rel.Field.FieldType.Kind() != reflect.Slice {
+		if len(children) > 0 {
+			setRelationField(rel.Field, model, children[len(children)-1])
+		}
+		return
+	}
+
+	field := rel.Field.ReflectValueOf(context.Background(), model)
+	for _, child := range children {
+		field.Set(reflect.Append(field, adaptPointer(child, field.Type().Elem())))
+	}
+}
+
+// setRelationField assigns a model pointer to a struct or pointer field.
+func setRelationField(field *schema.Field, model reflect.Value, value reflect.Value) {
+	f := field.ReflectValueOf(context.Background(), model)
+	f.Set(adaptPointer(value, f.Type()))
+}
+
+// adaptPointer converts a pointer to a model into the model when typ is not a pointer.
+func adaptPointer(value reflect.Value, typ reflect.Type) reflect.Value {
+	if value.Kind() == reflect.Ptr && typ.Kind() != reflect.Ptr {
+		return value.Elem()
+	}
+	if value.Kind() != reflect.Ptr && typ.Kind() == reflect.Ptr {
+		ptr := reflect.New(value.Type())
+		ptr.Elem().Set(value)
+		return ptr
+	}
+	return value
+}
+
+// containsRelationshipType checks if the relationship type is one of types.
+func containsRelationshipType(types []schema.RelationshipType, typ schema.RelationshipType) bool {
+	for _, t := range types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package factory
+
+import (
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testAuthor struct {
+	ID       uint
+	Username string
+	Posts    []testPost `gorm:"foreignKey:AuthorID"`
+	Profile  *testProfile
+}
+
+type testPost struct {
+	ID       uint
+	Title    string
+	AuthorID uint
+	Author   *testAuthor
+	Tags     []*testTag `gorm:"many2many:test_post_tags"`
+}
+
+type testProfile struct {
+	ID           uint
+	Bio          string
+	TestAuthorID uint
+}
+
+type testTag struct {
+	ID   uint
+	Name string
+}
+
+func testRelationDefinitions(db *gorm.DB) (*Definition[testAuthor], *Definition[testPost]) {
+	authors := Define(db, func() *testAuthor { return &testAuthor{Username: faker.Username()} })
+	posts := Define(db, func() *testPost { return &testPost{Title: faker.Sentence()} })
+	return authors, posts
+}
+
+func TestDefinition_Has(t *testing.T) {
+	db := testDB(t, &testAuthor{}, &testPost{}, &testProfile{}, &testTag{})
+	authors, posts := testRelationDefinitions(db)
+	profiles := Define(db, func() *testProfile { return &testProfile{Bio: faker.Sentence()} })
+
+	author := authors.Has(3, posts).Has(1, profiles).Create()
+	assert.Len(t, author.Posts, 3)
+	for _, post := range author.Posts {
+		assert.NotZero(t, post.ID)
+		assert.Equal(t, author.ID, post.AuthorID)
+	}
+	assert.Equal(t, author.ID, author.Profile.TestAuthorID)
+
+	var count int64
+	db.Model(&testPost{}).Where("author_id = ?", author.ID).Count(&count)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestDefinition_Has_ShouldWorkWithManyToMany(t *testing.T) {
+	db := testDB(t, &testAuthor{}, &testPost{}, &testTag{})
+	_, posts := testRelationDefinitions(db)
+	tags := Define(db, func() *testTag { return &testTag{Name: faker.Word()} })
+
+	post := posts.Has(2, tags).Create()
+
+	var loaded testPost
+	db.Preload("Tags").First(&loaded, post.ID)
+	assert.Len(t, loaded.Tags, 2)
+}
+
+func TestDefinition_For(t *testing.T) {
+	db := testDB(t, &testAuthor{}, &testPost{})
+	authors, posts := testRelationDefinitions(db)
+
+	author := authors.Create()
+	post := posts.For(author).Make()
+	assert.Equal(t, author.ID, post.AuthorID)
+
+	created := posts.For(author).Count(2).Create()
+	assert.Equal(t, author.ID, created[1].AuthorID)
+
+	var count int64
+	db.Model(&testAuthor{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestDefinition_For_ShouldCreateParent(t *testing.T) {
+	db := testDB(t, &testAuthor{}, &testPost{})
+	authors, posts := testRelationDefinitions(db)
+
+	created := posts.For(authors).Count(2).Create()
+	assert.NotZero(t, created[0].AuthorID)
+	assert.NotEqual(t, created[0].AuthorID, created[1].AuthorID)
+
+	var count int64
+	db.Model(&testAuthor{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestDefinition_Relations_ShouldPanicWithoutAssociation(t *testing.T) {
+	authors, posts := testRelationDefinitions(nil)
+
+	assert.Panics(t, func() { authors.For(posts) })
+	assert.Panics(t, func() { posts.Has(1, Define[testProfile](nil, nil)) })
+	assert.Panics(t, func() { authors.Has(1, posts, "Missing") })
+}
+
+func TestFactory_Make_ShouldOverrideSliceFields(t *testing.T) {
+	f := New(nil).Add(&testAccount{})
+	type withSlice struct{ Tags []string }
+
+	model := overwriteStructFields(&withSlice{Tags: []string{"a"}}, withSlice{Tags: []string{"b", "c"}}).(*withSlice)
+	assert.Equal(t, []string{"b", "c"}, model.Tags)
+
+	model = overwriteStructFields(&withSlice{Tags: []string{"a"}}, withSlice{}).(*withSlice)
+	assert.Equal(t, []string{"a"}, model.Tags)
+
+	assert.Equal(t, "admin", f.Make(testAccount{Role: "admin"}).(*testAccount).Role)
+}
@@ -0,0 +1,175 @@
+package napi
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// filterKeyPattern matches `filter[field]` and `filter[field][operator]` query keys.
+var filterKeyPattern = regexp.MustCompile(`^filter\[([A-Za-z0-9_.]+)\](?:\[([A-Za-z]+)\])?$`)
+
+// filterOperators every operator supported by the filter grammar.
+var filterOperators = []FilterOperator{FilterEq, FilterNeq, FilterGt, FilterGte, FilterLt, FilterLte, FilterLike, FilterIn}
+
+// Filter a single parsed condition, e.g. `filter[created_at][gte]=2024-01-01`. Values holds one value, or the comma separated values of FilterIn.
+type Filter struct {
+	Field    string
+	Operator FilterOperator
+	Values   []string
+}
+
+// Filters the conditions parsed from a query string, all of which must match.
+type Filters []Filter
+
+// FilterRules whitelists the fields of an endpoint that can be filtered and the operators allowed for each field.
+//
+//	napi.FilterRules{"status": {napi.FilterEq, napi.FilterIn}, "created_at": {napi.FilterGte, napi.FilterLte}}
+type FilterRules map[string][]FilterOperator
+
+// ParseFilters parses the `filter[...]` query parameters. The operator defaults to eq. Other query parameters are ignored.
+func ParseFilters(query url.Values) (Filters, *ValidationError) {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var filters Filters
+	bag := errorBag{}
+	for _, key := range keys {
+		if key != "filter" && !strings.HasPrefix(key, "filter[") {
+			continue
+		}
+
+		match := filterKeyPattern.FindStringSubmatch(key)
+		if match == nil {
+			bag[key] = "must look like filter[field] or filter[field][operator]"
+			continue
+		}
+
+		op := FilterEq
+		if match[2] != "" {
+			op = FilterOperator(strings.ToLower(match[2]))
+		}
+		if !containsOperator(filterOperators, op) {
+			bag[key] = fmt.Sprintf("unknown operator %s", op)
+			continue
+		}
+
+		for _, raw := range query[key] {
+			values, err := parseFilterValues(op, raw)
+			if err != "" {
+				bag[key] = err
+				break
+			}
+			filters = append(filters, Filter{Field: match[1], Operator: op, Values: values})
+		}
+	}
+
+	if len(bag) > 0 {
+		return nil, &ValidationError{bag: bag}
+	}
+	return filters, nil
+}
+
+// Validate checks every filter against the whitelist of fields and operators.
+func (f Filters) Validate(rules FilterRules) *ValidationError {
+	bag := errorBag{}
+	for _, filter := range f {
+		key := filter.key()
+		allowed, ok := rules[filter.Field]
+		if !ok {
+			bag[key] = fmt.Sprintf("filtering by %s is not allowed", filter.Field)
+			continue
+		}
+		if !containsOperator(allowed, filter.Operator) {
+			bag[key] = fmt.Sprintf("operator %s is not allowed for %s", filter.Operator, filter.Field)
+		}
+	}
+
+	if len(bag) > 0 {
+		return &ValidationError{bag: bag}
+	}
+	return nil
+}
+
+// Scope returns a gorm scope adding a where clause per filter. Fields are used as column names, so validate them first.
+// Values are converted to the type of the column in the model of the query, and the query fails with a ValidationFailedError when one does not convert.
+func (f Filters) Scope() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		bag := errorBag{}
+		for _, filter := range f {
+			expr, msg := filterExpression(filterColumnType(db, filter.Field), filter.Field, filter.Operator, filter.Values)
+			if msg != "" {
+				bag[filter.key()] = msg
+				continue
+			}
+			if expr != nil {
+				db = db.Where(expr)
+			}
+		}
+		if len(bag) > 0 {
+			_ = db.AddError((&ValidationError{bag: bag}).Err())
+		}
+		return db
+	}
+}
+
+// ParseFilters parses the `filter[...]` query parameters of the request into Filters and validates them against the rules. The returned error is meant to be sent with resp.Sender.FormError.
+func (req *CanFilter) ParseFilters(c *fiber.Ctx, rules FilterRules) *ValidationError {
+	query := url.Values{}
+	c.Context().QueryArgs().VisitAll(func(key, val []byte) {
+		query.Add(string(key), string(val))
+	})
+
+	filters, err := ParseFilters(query)
+	if err != nil {
+		return err
+	}
+	if err = filters.Validate(rules); err != nil {
+		return err
+	}
+
+	req.Filters = filters
+	return nil
+}
+
+// key formats the filter back into its query key, used in error messages.
+func (f Filter) key() string {
+	return fmt.Sprintf("filter[%s][%s]", f.Field, f.Operator)
+}
+
+// parseFilterValues splits the values of FilterIn. Returns an error message for empty values.
+func parseFilterValues(op FilterOperator, raw string) ([]string, string) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, "must have a value"
+	}
+	if op != FilterIn {
+		return []string{raw}, ""
+	}
+
+	values := strings.Split(raw, ",")
+	for i, v := range values {
+		if values[i] = strings.TrimSpace(v); values[i] == "" {
+			return nil, "must be a comma separated list of values"
+		}
+	}
+	return values, ""
+}
+
+// containsOperator checks if the slice contains the operator.
+func containsOperator(ops []FilterOperator, op FilterOperator) bool {
+	for _, item := range ops {
+		if item == op {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package napi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/netr/napi/resp"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func testFilterRules() FilterRules {
+	return FilterRules{
+		"status":   {FilterEq, FilterIn},
+		"age":      {FilterGte, FilterLt},
+		"username": {FilterLike},
+	}
+}
+
+func TestParseFilters_ExpectedBehavior(t *testing.T) {
+	query, _ := url.ParseQuery("filter[status]=active&filter[age][gte]=25&filter[role][in]=a,%20b&filter_by=ignored&page=2")
+
+	filters, err := ParseFilters(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, Filters{
+		{Field: "age", Operator: FilterGte, Values: []string{"25"}},
+		{Field: "role", Operator: FilterIn, Values: []string{"a", "b"}},
+		{Field: "status", Operator: FilterEq, Values: []string{"active"}},
+	}, filters)
+}
+
+func TestParseFilters_ShouldReportSyntaxErrors(t *testing.T) {
+	query, _ := url.ParseQuery("filter[status]=&filter[age][between]=1&filter[role][in]=a,,b&filter[x]]=1&filter=1")
+
+	_, err := ParseFilters(query)
+	if err == nil {
+		t.Fatal("should have returned a validation error")
+	}
+
+	assert.Equal(t, map[string]string{
+		"filter[status]":       "must have a value",
+		"filter[age][between]": "unknown operator between",
+		"filter[role][in]":     "must be a comma separated list of values",
+		"filter[x]]":           "must look like filter[field] or filter[field][operator]",
+		"filter":               "must look like filter[field] or filter[field][operator]",
+	}, err.Error())
+}
+
+func TestFilters_Validate_ShouldUseTheWhitelist(t *testing.T) {
+	filters := Filters{
+		{Field: "status", Operator: FilterIn, Values: []string{"a"}},
+		{Field: "password", Operator: FilterEq, Values: []string{"secret"}},
+		{Field: "age", Operator: FilterLike, Values: []string{"2"}},
+	}
+
+	err := filters.Validate(testFilterRules())
+	if err == nil {
+		t.Fatal("should have returned a validation error")
+	}
+
+	assert.Equal(t, map[string]string{
+		"filter[password][eq]": "filtering by password is not allowed",
+		"filter[age][like]":    "operator like is not allowed for age",
+	}, err.Error())
+}
+
+func TestFilters_Scope_ExpectedBehavior(t *testing.T) {
+	db := testSeedScopeModels(t)
+	filters := Filters{
+		{Field: "status", Operator: FilterIn, Values: []string{"active", "banned"}},
+		{Field: "age", Operator: FilterLt, Values: []string{"30"}},
+	}
+
+	var items []testScopeModel
+	if err := db.Scopes(filters.Scope()).Order("id").Find(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"bob", "erin"}, testScopeUsernames(items))
+}
+
+func TestFilters_Scope_ShouldBindValuesAsTheColumnType(t *testing.T) {
+	db := testSeedScopeModels(t)
+	filters := Filters{
+		{Field: "age", Operator: FilterIn, Values: []string{"20", "25"}},
+		{Field: "status", Operator: FilterEq, Values: []string{"1"}},
+	}
+
+	var items []testScopeModel
+	stmt := db.Session(&gorm.Session{DryRun: true}).Scopes(filters.Scope()).Find(&items).Statement
+	assert.Equal(t, []interface{}{int64(20), int64(25), "1"}, stmt.Vars)
+
+	filters = Filters{{Field: "age", Operator: FilterGte, Values: []string{"abc"}}}
+	err := db.Scopes(filters.Scope()).Find(&items).Error
+	var vf *ValidationFailedError
+	if assert.ErrorAs(t, err, &vf) {
+		assert.Equal(t, map[string]string{"filter[age][gte]": "must be an integer"}, vf.Validation.Error())
+	}
+}
+
+func TestCanFilter_ParseFilters_ShouldSendFormErrors(t *testing.T) {
+	type indexRequest struct {
+		Paginater
+	}
+
+	app := fiber.New()
+	app.Get("/users", func(c *fiber.Ctx) error {
+		req := new(indexRequest)
+		if err := c.QueryParser(req); err != nil {
+			return err
+		}
+		if err := req.ParseFilters(c, testFilterRules()); err != nil {
+			return resp.New(c).FormError("invalid filters", err.Error())
+		}
+		return resp.New(c).Success("users", len(req.Filters))
+	})
+
+	res, err := app.Test(httptest.NewRequest("GET", "/users?page=1&filter[status][in]=a,b&filter[age][gte]=3", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = app.Test(httptest.NewRequest("GET", "/users?filter[password]=x", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+}
+
+func TestPaginate_ShouldApplyParsedFilters(t *testing.T) {
+	db := testSeedScopeModels(t)
+	p := &Paginater{CanPaginate: CanPaginate{Page: 1, Limit: 1}}
+	p.Filters = Filters{{Field: "status", Operator: FilterEq, Values: []string{"active"}}}
+
+	items, total, err := Paginate[testScopeModel](db, p, ScopeOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Len(t, items, 1)
+}
@@ -0,0 +1,239 @@
+// Package health
+// Named readiness and liveness checks served as JSON health endpoints.
+
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// StatusOK is reported for passing checks and healthy reports.
+	StatusOK = "OK"
+	// StatusFail is reported for failing checks and unhealthy reports.
+	StatusFail = "FAIL"
+
+	defaultTimeout  = time.Second * 5
+	defaultCacheTTL = time.Second
+)
+
+// CheckFunc checks a single dependency. A nil error means the dependency is healthy.
+type CheckFunc func(ctx context.Context) error
+
+// Observer is notified every time a check is run, e.g. to export its state as a metric.
+type Observer interface {
+	ObserveHealthCheck(name string, healthy bool, duration time.Duration)
+}
+
+// CheckResult is the outcome of a single check.
+type CheckResult struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is the outcome of all the checks of a kind, keyed by check name.
+type Report struct {
+	Message string                 `json:"message"`
+	Checks  map[string]CheckResult `json:"checks"`
+}
+
+// Healthy checks if every check in the report passed.
+func (r Report) Healthy() bool {
+	return r.Message == StatusOK
+}
+
+// Registry holds the named liveness and readiness checks.
+type Registry struct {
+	lock      *sync.RWMutex
+	liveness  map[string]*check
+	readiness map[string]*check
+	timeout   time.Duration
+	cacheTTL  time.Duration
+	observer  Observer
+}
+
+// Option type used for option pattern
+type Option func(*Registry)
+
+// WithTimeout sets how long a single check may take before it is reported as failed. Default is 5 seconds.
+func WithTimeout(d time.Duration) Option {
+	return func(r *Registry) {
+		r.timeout = d
+	}
+}
+
+// WithCacheTTL sets how long a check result is reused before the check is run again. Default is 1 second, 0 disables caching.
+func WithCacheTTL(d time.Duration) Option {
+	return func(r *Registry) {
+		r.cacheTTL = d
+	}
+}
+
+// WithObserver sets the Observer notified about every check run.
+func WithObserver(o Observer) Option {
+	return func(r *Registry) {
+		r.observer = o
+	}
+}
+
+// New creates a new Registry with stackable options using the options pattern.
+func New(opts ...Option) *Registry {
+	r := &Registry{
+		lock:      new(sync.RWMutex),
+		liveness:  map[string]*check{},
+		readiness: map[string]*check{},
+		timeout:   defaultTimeout,
+		cacheTTL:  defaultCacheTTL,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// SetObserver sets the Observer notified about every check run.
+func (r *Registry) SetObserver(o Observer) *Registry {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.observer = o
+	return r
+}
+
+// AddReadiness registers a readiness check under a name. Readiness checks cover the dependencies needed to serve traffic, e.g. the database.
+func (r *Registry) AddReadiness(name string, fn CheckFunc) *Registry {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.readiness[name] = &check{name: name, fn: fn}
+	return r
+}
+
+// AddLiveness registers a liveness check under a name. Liveness checks should only fail when the process needs to be restarted.
+func (r *Registry) AddLiveness(name string, fn CheckFunc) *Registry {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.liveness[name] = &check{name: name, fn: fn}
+	return r
+}
+
+// Live runs the liveness checks.
+func (r *Registry) Live(ctx context.Context) Report {
+	return r.run(ctx, r.liveness)
+}
+
+// Ready runs the readiness checks.
+func (r *Registry) Ready(ctx context.Context) Report {
+	return r.run(ctx, r.readiness)
+}
+
+// LiveHandler serves the liveness report. Responds with http.StatusServiceUnavailable when a check fails.
+func (r *Registry) LiveHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return sendReport(c, r.Live(c.UserContext()))
+	}
+}
+
+// ReadyHandler serves the readiness report. Responds with http.StatusServiceUnavailable when a check fails.
+func (r *Registry) ReadyHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return sendReport(c, r.Ready(c.UserContext()))
+	}
+}
+
+// run runs the given checks concurrently and builds a report.
+func (r *Registry) run(ctx context.Context, checks map[string]*check) Report {
+	r.lock.RLock()
+	list := make([]*check, 0, len(checks))
+	for _, c := range checks {
+		list = append(list, c)
+	}
+	timeout, cacheTTL, observer := r.timeout, r.cacheTTL, r.observer
+	r.lock.RUnlock()
+
+	results := make([]CheckResult, len(list))
+	var wg sync.WaitGroup
+	for i, c := range list {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i] = c.run(ctx, timeout, cacheTTL, observer)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Message: StatusOK, Checks: make(map[string]CheckResult, len(list))}
+	for i, c := range list {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Message = StatusFail
+		}
+	}
+	return report
+}
+
+// check is a registered check with its cached result.
+type check struct {
+	name string
+	fn   CheckFunc
+	lock sync.Mutex
+	last *CheckResult
+}
+
+// run runs the check, or returns the cached result while it is still fresh. Concurrent callers wait for a single run.
+func (c *check) run(ctx context.Context, timeout, cacheTTL time.Duration, observer Observer) CheckResult {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.last != nil && cacheTTL > 0 && time.Since(c.last.CheckedAt) < cacheTTL {
+		return *c.last
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	elapsed := time.Since(start)
+
+	res := CheckResult{Status: StatusOK, Duration: elapsed.String(), CheckedAt: start}
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+
+	if observer != nil {
+		observer.ObserveHealthCheck(c.name, err == nil, elapsed)
+	}
+
+	c.last = &res
+	return res
+}
+
+// sendReport sends the report with a status code matching its health.
+func sendReport(c *fiber.Ctx, report Report) error {
+	code := http.StatusOK
+	if !report.Healthy() {
+		code = http.StatusServiceUnavailable
+	}
+	return c.Status(code).JSON(report)
+}
@@ -0,0 +1,134 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/gofiber/fiber/v2"
+	"github.com/netr/napi/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	glog "gorm.io/gorm/logger"
+)
+
+func TestRegistry_Ready_ReportsEveryCheck(t *testing.T) {
+	r := New().
+		AddReadiness("ok", func(ctx context.Context) error { return nil }).
+		AddReadiness("fail", func(ctx context.Context) error { return errors.New("down") })
+
+	report := r.Ready(context.Background())
+
+	assert.False(t, report.Healthy())
+	assert.Equal(t, StatusOK, report.Checks["ok"].Status)
+	assert.Equal(t, StatusFail, report.Checks["fail"].Status)
+	assert.Equal(t, "down", report.Checks["fail"].Error)
+}
+
+func TestRegistry_Live_IsHealthyWithoutChecks(t *testing.T) {
+	r := New().AddReadiness("fail", func(ctx context.Context) error { return errors.New("down") })
+
+	assert.True(t, r.Live(context.Background()).Healthy())
+}
+
+func TestRegistry_ShouldFailChecksThatTimeOut(t *testing.T) {
+	r := New(WithTimeout(10*time.Millisecond)).
+		AddReadiness("slow", func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		})
+
+	report := r.Ready(context.Background())
+
+	assert.Equal(t, StatusFail, report.Checks["slow"].Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestRegistry_ShouldCacheResults(t *testing.T) {
+	var calls int32
+	r := New(WithCacheTTL(time.Minute)).
+		AddReadiness("counted", func(ctx context.Context) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		})
+
+	r.Ready(context.Background())
+	r.Ready(context.Background())
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRegistry_ReadyHandler_ShouldRespondUnavailable(t *testing.T) {
+	app := fiber.New()
+	r := New().AddReadiness("fail", func(ctx context.Context) error { return errors.New("down") })
+	app.Get("/ready", r.ReadyHandler())
+
+	res, err := app.Test(httptest.NewRequest("GET", "/ready", nil), 15000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+}
+
+func TestRegistry_ShouldExportStateThroughPrometheus(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	prom := middleware.NewWithRegistry(registry, "test", "http", "", nil)
+	r := New(WithObserver(prom)).
+		AddReadiness("ok", func(ctx context.Context) error { return nil }).
+		AddReadiness("fail", func(ctx context.Context) error { return errors.New("down") })
+
+	r.Ready(context.Background())
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "http_health_check_status" {
+			continue
+		}
+		assert.Equal(t, 2, len(family.GetMetric()))
+		for _, m := range family.GetMetric() {
+			// labels are sorted by name: check, service
+			want := 1.0
+			if m.GetLabel()[0].GetValue() == "fail" {
+				want = 0
+			}
+			assert.Equal(t, want, m.GetGauge().GetValue())
+		}
+		return
+	}
+	t.Fatal("should have exported http_health_check_status")
+}
+
+func TestGorm_ExpectedBehavior(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: glog.Default.LogMode(glog.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, Gorm(db)(context.Background()))
+
+	sqlDB, _ := db.DB()
+	_ = sqlDB.Close()
+	assert.Error(t, Gorm(db)(context.Background()))
+}
+
+func TestRedis_ExpectedBehavior(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+
+	assert.NoError(t, Redis(client)(context.Background()))
+
+	s.Close()
+	assert.Error(t, Redis(client)(context.Background()))
+}
@@ -0,0 +1,43 @@
+package napi
+
+import (
+	"context"
+	"strings"
+)
+
+// Hook is a server lifecycle callback registered with OnStart or OnShutdown.
+type Hook func(ctx context.Context) error
+
+// MultiError collects the errors returned while running and shutting down the server.
+type MultiError []error
+
+// Error joins all the collected error messages.
+func (m MultiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, err := range m {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ErrorOrNil returns nil when no errors were collected. Prevents returning a non-nil error interface holding an empty MultiError.
+func (m MultiError) ErrorOrNil() error {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// runHooks runs the hooks in order and collects their errors. When failFast is set, the first error stops the remaining hooks from running.
+func runHooks(ctx context.Context, hooks []Hook, failFast bool) MultiError {
+	var errs MultiError
+	for _, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			errs = append(errs, err)
+			if failFast {
+				break
+			}
+		}
+	}
+	return errs
+}
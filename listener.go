@@ -0,0 +1,88 @@
+package napi
+
+import (
+	"fmt"
+	"net"
+	"os"
+)
+
+// WithUnixSocket serve on a Unix domain socket instead of a TCP port. The socket file is created with the given mode and removed on graceful shutdown.
+func WithUnixSocket(path string, mode os.FileMode) ServerOption {
+	return func(s *Server) {
+		s.UseUnixSocket(path, mode)
+	}
+}
+
+// WithListener serve on a pre-opened net.Listener instead of a TCP port, e.g. a listener handed over by systemd socket activation.
+func WithListener(ln net.Listener) ServerOption {
+	return func(s *Server) {
+		s.UseListener(ln)
+	}
+}
+
+// UseUnixSocket helper function to serve on a Unix domain socket instead of a TCP port.
+func (s *Server) UseUnixSocket(path string, mode os.FileMode) *Server {
+	s.unixSocket = path
+	s.unixSocketMode = mode
+	return s
+}
+
+// UseListener helper function to serve on a pre-opened net.Listener instead of a TCP port.
+func (s *Server) UseListener(ln net.Listener) *Server {
+	s.listener = ln
+	return s
+}
+
+// usesCustomListener checks if the server needs a listener other than the default TCP port.
+func (s *Server) usesCustomListener() bool {
+	return s.listener != nil || s.unixSocket != ""
+}
+
+// netListener returns the pre-opened listener, a Unix socket listener or a TCP listener on the configured port.
+func (s *Server) netListener() (net.Listener, error) {
+	if s.listener != nil {
+		return s.listener, nil
+	}
+	if s.unixSocket != "" {
+		return listenUnix(s.unixSocket, s.unixSocketMode)
+	}
+	return net.Listen(s.app.Config().Network, fmt.Sprintf(":%d", s.port))
+}
+
+// removeUnixSocket removes the socket file, if one is used. Missing files are ignored since closing the listener usually unlinks it.
+func (s *Server) removeUnixSocket() error {
+	if s.unixSocket == "" {
+		return nil
+	}
+	if err := os.Remove(s.unixSocket); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// listenUnix listens on a Unix domain socket and applies the file mode. Stale socket files left behind by a crashed process are removed first.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if conn, err := net.Dial("unix", path); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("unix socket %s is already in use", path)
+		}
+		if err = os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode != 0 {
+		if err = os.Chmod(path, mode); err != nil {
+			_ = ln.Close()
+			return nil, err
+		}
+	}
+
+	return ln, nil
+}
@@ -0,0 +1,109 @@
+package napi
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestWithUnixSocket_ExpectedBehavior(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "napi.sock")
+	s := NewServer(
+		fiber.Config{DisableStartupMessage: true},
+		WithUnixSocket(path, 0660),
+	)
+	s.App().Get("/ping", func(c *fiber.Ctx) error {
+		return c.SendString("pong")
+	})
+	stop := testRunServer(t, s, "unix", path)
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0660 {
+		t.Fatalf("wanted mode 0660, got: %o\n", fi.Mode().Perm())
+	}
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	if body := testHTTPGet(t, client, "http://unix/ping"); body != "pong" {
+		t.Fatalf("wanted pong, got: %s\n", body)
+	}
+
+	stop()
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("should have removed the socket file on shutdown")
+	}
+}
+
+func TestWithUnixSocket_ShouldReplaceStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "napi.sock")
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// leave the file behind, as a crashed process would
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	s := NewServer(
+		fiber.Config{DisableStartupMessage: true},
+		WithUnixSocket(path, 0),
+	)
+	stop := testRunServer(t, s, "unix", path)
+	stop()
+}
+
+func TestWithUnixSocket_ShouldRemoveSocketWhenAListenerFails(t *testing.T) {
+	busy, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+
+	path := filepath.Join(t.TempDir(), "napi.sock")
+	s := NewServer(
+		fiber.Config{DisableStartupMessage: true},
+		WithUnixSocket(path, 0),
+		WithAdminPort(busy.Addr().(*net.TCPAddr).Port),
+		WithShutdownTimeout(time.Second),
+	)
+
+	if err = s.RunContext(context.Background()); err == nil {
+		t.Fatal("should have returned the error of the admin listener")
+	}
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("should have removed the socket file after the failure")
+	}
+}
+
+func TestWithListener_ExpectedBehavior(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewServer(
+		fiber.Config{DisableStartupMessage: true},
+		WithListener(ln),
+	)
+	s.App().Get("/ping", func(c *fiber.Ctx) error {
+		return c.SendString("pong")
+	})
+	stop := testRunServer(t, s, "tcp", ln.Addr().String())
+	defer stop()
+
+	if body := testHTTPGet(t, http.DefaultClient, "http://"+ln.Addr().String()+"/ping"); body != "pong" {
+		t.Fatalf("wanted pong, got: %s\n", body)
+	}
+}
@@ -0,0 +1,575 @@
+package napi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ErrUnsupportedCondition is returned by MemoryRepository for conditions it cannot evaluate, like raw SQL strings.
+var ErrUnsupportedCondition = errors.New("memory repository: unsupported condition")
+
+// MemoryRepository is a map-backed, concurrency-safe Repository[T] for unit tests that don't need a database.
+//
+// Models are parsed like gorm does: integer primary keys are auto-incremented, autoCreateTime/autoUpdateTime fields are set, and
+// conditions may be a primary key, a struct (non-zero fields must match) or a map keyed by column or field name.
+// Models are stored and returned as copies.
+type MemoryRepository[T any] struct {
+	lock   *sync.RWMutex
+	schema *schema.Schema
+	items  map[string]T
+	keys   []string
+	nextID uint64
+}
+
+// NewMemoryRepository creates an empty MemoryRepository. Panics if T cannot be parsed as a gorm model.
+func NewMemoryRepository[T any]() *MemoryRepository[T] {
+	s, err := schema.Parse(new(T), &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		panic(fmt.Errorf("memory repository: %w", err))
+	}
+	if s.PrioritizedPrimaryField == nil {
+		panic(fmt.Errorf("memory repository: %s has no primary key", s.Name))
+	}
+
+	return &MemoryRepository[T]{
+		lock:   new(sync.RWMutex),
+		schema: s,
+		items:  map[string]T{},
+	}
+}
+
+// Find gets a model by primary key. Returns gorm.ErrRecordNotFound if it does not exist.
+func (r *MemoryRepository[T]) Find(id interface{}) (*T, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	model, ok := r.items[fmt.Sprint(id)]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &model, nil
+}
+
+// FindBy gets every model matching the conditions, ordered by insertion.
+func (r *MemoryRepository[T]) FindBy(conds ...interface{}) ([]T, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.filter(conds)
+}
+
+// First gets the first model matching the conditions. Returns gorm.ErrRecordNotFound if none match.
+func (r *MemoryRepository[T]) First(conds ...interface{}) (*T, error) {
+	models, err := r.FindBy(conds...)
+	if err != nil {
+		return nil, err
+	}
+	if len(models) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &models[0], nil
+}
+
+// Create stores a copy of the model, assigning an auto-incremented primary key when it is zero.
+func (r *MemoryRepository[T]) Create(model *T) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.create(model)
+}
+
+// CreateMany stores copies of the models. Primary keys are assigned to the given slice.
+func (r *MemoryRepository[T]) CreateMany(models []T) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i := range models {
+		if err := r.create(&models[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update sets the values, keyed by column or field name, on a model and returns the updated copy. Returns gorm.ErrRecordNotFound if it does not exist.
+func (r *MemoryRepository[T]) Update(id interface{}, values UpdateMap) (*T, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	key := fmt.Sprint(id)
+	model, ok := r.items[key]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	ctx := context.Background()
+	rv := reflect.ValueOf(&model).Elem()
+	for name, value := range values {
+		field := r.schema.LookUpField(name)
+		if field == nil {
+			return nil, fmt.Errorf("memory repository: unknown field %s", name)
+		}
+		if err := field.Set(ctx, rv, value); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.touch(rv, false); err != nil {
+		return nil, err
+	}
+
+	r.items[key] = model
+	return &model, nil
+}
+
+// Delete deletes a model by primary key. Missing models are ignored.
+func (r *MemoryRepository[T]) Delete(id interface{}) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	key := fmt.Sprint(id)
+	if _, ok := r.items[key]; !ok {
+		return nil
+	}
+	delete(r.items, key)
+	for i, k := range r.keys {
+		if k == key {
+			r.keys = append(r.keys[:i], r.keys[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Exists checks if any model matches the conditions.
+func (r *MemoryRepository[T]) Exists(conds ...interface{}) (bool, error) {
+	count, err := r.Count(conds...)
+	return count > 0, err
+}
+
+// Count counts the models matching the conditions.
+func (r *MemoryRepository[T]) Count(conds ...interface{}) (int64, error) {
+	models, err := r.FindBy(conds...)
+	return int64(len(models)), err
+}
+
+// Paginate lists a page of models and counts every match. Supports the same filters, search and ordering as Paginate, evaluated in memory.
+func (r *MemoryRepository[T]) Paginate(p *Paginater, opts ScopeOptions) ([]T, int64, error) {
+	models, err := r.FindBy()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var filters Filters
+	values := p.FilterValues()
+	for col, op := range opts.Filters {
+		if vals, ok := values[col]; ok {
+			if op == FilterIn {
+				vals = splitFilterValues(vals, "|")
+			}
+			filters = append(filters, Filter{Field: col, Operator: op, Values: vals})
+		}
+	}
+	filters = append(filters, p.Filters...)
+
+	matched := make([]T, 0, len(models))
+	for _, model := range models {
+		rv := reflect.ValueOf(&model).Elem()
+		ok, err := r.matchFilters(rv, filters)
+		if err != nil {
+			return nil, 0, err
+		}
+		if ok && r.matchSearch(rv, p.Search, opts.SearchColumns) {
+			matched = append(matched, model)
+		}
+	}
+
+	if err = r.sort(matched, r.sorts(p, opts)); err != nil {
+		return nil, 0, err
+	}
+
+	total := int64(len(matched))
+	if p.Limit > 0 {
+		start := p.Offset()
+		if start < 0 {
+			start = 0
+		}
+		if start > len(matched) {
+			start = len(matched)
+		}
+		end := start + p.Limit
+		if end > len(matched) {
+			end = len(matched)
+		}
+		matched = matched[start:end]
+	}
+	return matched, total, nil
+}
+
+// create stores the model. Callers must hold the write lock.
+func (r *MemoryRepository[T]) create(model *T) error {
+	ctx := context.Background()
+	rv := reflect.ValueOf(model).Elem()
+	pk := r.schema.PrioritizedPrimaryField
+
+	id, zero := pk.ValueOf(ctx, rv)
+	if zero {
+		switch pk.FieldType.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			r.nextID++
+			if err := pk.Set(ctx, rv, r.nextID); err != nil {
+				return err
+			}
+			id, _ = pk.ValueOf(ctx, rv)
+		default:
+			return fmt.Errorf("memory repository: %s requires a primary key", r.schema.Name)
+		}
+	} else if n, err := strconv.ParseUint(fmt.Sprint(id), 10, 64); err == nil && n > r.nextID {
+		r.nextID = n
+	}
+
+	key := fmt.Sprint(id)
+	if _, ok := r.items[key]; ok {
+		return fmt.Errorf("memory repository: duplicate primary key %s", key)
+	}
+	if err := r.touch(rv, true); err != nil {
+		return err
+	}
+
+	r.items[key] = *model
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+// touch sets the autoUpdateTime fields, and the zero autoCreateTime fields when creating.
+func (r *MemoryRepository[T]) touch(rv reflect.Value, creating bool) error {
+	ctx := context.Background()
+	now := time.Now()
+	for _, field := range r.schema.Fields {
+		set := field.AutoUpdateTime > 0
+		if creating && field.AutoCreateTime > 0 {
+			_, zero := field.ValueOf(ctx, rv)
+			set = set || zero
+		}
+		if set {
+			if err := field.Set(ctx, rv, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// filter returns copies of the models matching the conditions. Callers must hold the read lock.
+func (r *MemoryRepository[T]) filter(conds []interface{}) ([]T, error) {
+	match, err := r.matcher(conds)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]T, 0)
+	for _, key := range r.keys {
+		model := r.items[key]
+		if match(key, reflect.ValueOf(&model).Elem()) {
+			models = append(models, model)
+		}
+	}
+	return models, nil
+}
+
+// matcher builds the predicate of gorm style inline conditions.
+func (r *MemoryRepository[T]) matcher(conds []interface{}) (func(key string, rv reflect.Value) bool, error) {
+	if len(conds) == 0 {
+		return func(string, reflect.Value) bool { return true }, nil
+	}
+	if len(conds) > 1 {
+		return nil, ErrUnsupportedCondition
+	}
+
+	ctx := context.Background()
+	cond := reflect.Indirect(reflect.ValueOf(conds[0]))
+	switch {
+	case !cond.IsValid():
+		return nil, ErrUnsupportedCondition
+	case cond.Type() == r.schema.ModelType:
+		var fields []*schema.Field
+		var want []interface{}
+		for _, field := range r.schema.Fields {
+			if value, zero := field.ValueOf(ctx, cond); field.DBName != "" && !zero {
+				fields = append(fields, field)
+				want = append(want, value)
+			}
+		}
+		return func(_ string, rv reflect.Value) bool {
+			for i, field := range fields {
+				if value, _ := field.ValueOf(ctx, rv); !valuesEqual(value, want[i]) {
+					return false
+				}
+			}
+			return true
+		}, nil
+	case cond.Kind() == reflect.Map && cond.Type().Key().Kind() == reflect.String:
+		fields := map[*schema.Field]interface{}{}
+		for _, k := range cond.MapKeys() {
+			field := r.schema.LookUpField(k.String())
+			if field == nil {
+				return nil, fmt.Errorf("memory repository: unknown field %s", k.String())
+			}
+			fields[field] = cond.MapIndex(k).Interface()
+		}
+		return func(_ string, rv reflect.Value) bool {
+			for field, want := range fields {
+				if value, _ := field.ValueOf(ctx, rv); !valuesEqual(value, want) {
+					return false
+				}
+			}
+			return true
+		}, nil
+	case cond.Kind() == reflect.String:
+		if _, err := strconv.ParseInt(cond.String(), 10, 64); err != nil {
+			return nil, ErrUnsupportedCondition
+		}
+		fallthrough
+	case cond.CanInt() || cond.CanUint():
+		id := fmt.Sprint(cond.Interface())
+		return func(key string, _ reflect.Value) bool { return key == id }, nil
+	}
+	return nil, ErrUnsupportedCondition
+}
+
+// matchFilters checks every filter against the model, like the where clauses of Filters.Scope.
+func (r *MemoryRepository[T]) matchFilters(rv reflect.Value, filters Filters) (bool, error) {
+	ctx := context.Background()
+	for _, filter := range filters {
+		field := r.schema.LookUpField(filter.Field)
+		if field == nil {
+			return false, fmt.Errorf("memory repository: unknown field %s", filter.Field)
+		}
+		value, _ := field.ValueOf(ctx, rv)
+
+		matched := false
+		switch filter.Operator {
+		case FilterIn:
+			for _, v := range filter.Values {
+				if cmp, ok := compareValue(value, v); ok && cmp == 0 {
+					matched = true
+					break
+				}
+			}
+		case FilterLike:
+			matched = containsFold(value, filter.Values[len(filter.Values)-1])
+		default:
+			cmp, ok := compareValue(value, filter.Values[len(filter.Values)-1])
+			matched = ok && map[FilterOperator]bool{
+				FilterEq:  cmp == 0,
+				FilterNeq: cmp != 0,
+				FilterGt:  cmp > 0,
+				FilterGte: cmp >= 0,
+				FilterLt:  cmp < 0,
+				FilterLte: cmp <= 0,
+			}[filter.Operator]
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matchSearch checks if any of the columns contains the search, case-insensitive like SQLite's LIKE.
+func (r *MemoryRepository[T]) matchSearch(rv reflect.Value, search string, columns []string) bool {
+	if search == "" || len(columns) == 0 {
+		return true
+	}
+	for _, col := range columns {
+		if field := r.schema.LookUpField(col); field != nil {
+			if value, _ := field.ValueOf(context.Background(), rv); containsFold(value, search) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sorts resolves the ordering of Paginate from the sort rules or the order columns.
+func (r *MemoryRepository[T]) sorts(p *Paginater, opts ScopeOptions) []Sort {
+	if opts.Sort != nil {
+		return p.Sorts(opts.Sort)
+	}
+	if containsString(opts.OrderColumns, p.OrderBy) {
+		return []Sort{{Column: p.OrderBy, Desc: strings.EqualFold(p.OrderDir, "desc")}}
+	}
+	return nil
+}
+
+// sort orders the models in place. Ties keep the insertion order.
+func (r *MemoryRepository[T]) sort(models []T, sorts []Sort) error {
+	ctx := context.Background()
+	fields := make([]*schema.Field, len(sorts))
+	for i, s := range sorts {
+		if fields[i] = r.schema.LookUpField(s.Column); fields[i] == nil {
+			return fmt.Errorf("memory repository: unknown field %s", s.Column)
+		}
+	}
+
+	sort.SliceStable(models, func(i, j int) bool {
+		a, b := reflect.ValueOf(&models[i]).Elem(), reflect.ValueOf(&models[j]).Elem()
+		for k, s := range sorts {
+			av, _ := fields[k].ValueOf(ctx, a)
+			bv, _ := fields[k].ValueOf(ctx, b)
+
+			aNil, bNil := isNilValue(av), isNilValue(bv)
+			if aNil != bNil {
+				if s.Nulls == NullsDefault {
+					// NULLs are the smallest values, like SQLite
+					return aNil != s.Desc
+				}
+				return aNil == (s.Nulls == NullsFirst)
+			}
+			if aNil {
+				continue
+			}
+
+			if cmp := compareValues(av, bv); cmp != 0 {
+				return (cmp < 0) != s.Desc
+			}
+		}
+		return false
+	})
+	return nil
+}
+
+// valuesEqual compares a model value with a condition value, converting between numeric types and strings.
+func valuesEqual(a, b interface{}) bool {
+	if at, ok := derefValue(a).(time.Time); ok {
+		bt, ok := derefValue(b).(time.Time)
+		return ok && at.Equal(bt)
+	}
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	return fmt.Sprint(derefValue(a)) == fmt.Sprint(derefValue(b))
+}
+
+// compareValue compares a model value with a raw filter value parsed into the model value's type. NULLs never match.
+func compareValue(value interface{}, raw string) (int, bool) {
+	value = derefValue(value)
+	switch v := value.(type) {
+	case nil:
+		return 0, false
+	case time.Time:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"} {
+			if t, err := time.Parse(layout, raw); err == nil {
+				return compareValues(v, t), true
+			}
+		}
+		return 0, false
+	case bool:
+		b, err := strconv.ParseBool(raw)
+		return compareValues(v, b), err == nil
+	case string:
+		return strings.Compare(v, raw), true
+	}
+
+	if f, ok := toFloat(value); ok {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		return compareValues(f, parsed), err == nil
+	}
+	return strings.Compare(fmt.Sprint(value), raw), true
+}
+
+// compareValues orders two values of the same type.
+func compareValues(a, b interface{}) int {
+	a, b = derefValue(a), derefValue(b)
+	switch av := a.(type) {
+	case time.Time:
+		bv, _ := b.(time.Time)
+		switch {
+		case av.Before(bv):
+			return -1
+		case av.After(bv):
+			return 1
+		}
+		return 0
+	case bool:
+		bv, _ := b.(bool)
+		switch {
+		case av == bv:
+			return 0
+		case !av:
+			return -1
+		}
+		return 1
+	case string:
+		bv, _ := b.(string)
+		return strings.Compare(av, bv)
+	}
+
+	af, aok := toFloat(a)
+	bf, bok := toFloat(b)
+	if aok && bok {
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// toFloat converts numeric values to float64.
+func toFloat(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch {
+	case !rv.IsValid():
+		return 0, false
+	case rv.CanInt():
+		return float64(rv.Int()), true
+	case rv.CanUint():
+		return float64(rv.Uint()), true
+	case rv.CanFloat():
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// containsFold checks if the value contains the substring, ignoring case. NULLs never match.
+func containsFold(value interface{}, substr string) bool {
+	value = derefValue(value)
+	if value == nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(fmt.Sprint(value)), strings.ToLower(substr))
+}
+
+// derefValue dereferences pointers, returning nil for nil pointers.
+func derefValue(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	for rv.IsValid() && rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	return rv.Interface()
+}
+
+// isNilValue checks for nil values and nil pointers.
+func isNilValue(v interface{}) bool {
+	return derefValue(v) == nil
+}
@@ -0,0 +1,193 @@
+package napi
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testMemoryModel struct {
+	ID        uint
+	Username  string
+	Status    string
+	Age       int
+	Nickname  *string
+	CreatedAt time.Time
+	UpdatedAt int64 `gorm:"autoUpdateTime"`
+}
+
+var _ Repository[testMemoryModel] = NewMemoryRepository[testMemoryModel]()
+
+func testMemoryRepository(t *testing.T) *MemoryRepository[testMemoryModel] {
+	nick := "al"
+	repo := NewMemoryRepository[testMemoryModel]()
+	if err := repo.CreateMany([]testMemoryModel{
+		{Username: "alice", Status: "active", Age: 30, Nickname: &nick},
+		{Username: "bob", Status: "banned", Age: 25},
+		{Username: "carol", Status: "active", Age: 41},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestMemoryRepository_Create(t *testing.T) {
+	repo := testMemoryRepository(t)
+
+	model := &testMemoryModel{Username: "dave"}
+	assert.NoError(t, repo.Create(model))
+	assert.Equal(t, uint(4), model.ID)
+	assert.False(t, model.CreatedAt.IsZero())
+	assert.NotZero(t, model.UpdatedAt)
+
+	assert.NoError(t, repo.Create(&testMemoryModel{ID: 10, Username: "erin"}))
+	assert.Error(t, repo.Create(&testMemoryModel{ID: 10, Username: "frank"}))
+
+	model = &testMemoryModel{Username: "grace"}
+	assert.NoError(t, repo.Create(model))
+	assert.Equal(t, uint(11), model.ID)
+}
+
+func TestMemoryRepository_Find(t *testing.T) {
+	repo := testMemoryRepository(t)
+
+	model, err := repo.Find(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", model.Username)
+
+	model.Username = "changed"
+	found, _ := repo.Find("2")
+	assert.Equal(t, "bob", found.Username)
+
+	_, err = repo.Find(42)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestMemoryRepository_FindBy(t *testing.T) {
+	repo := testMemoryRepository(t)
+
+	models, err := repo.FindBy(&testMemoryModel{Status: "active"})
+	assert.NoError(t, err)
+	assert.Len(t, models, 2)
+
+	models, err = repo.FindBy(map[string]interface{}{"status": "active", "Age": 41})
+	assert.NoError(t, err)
+	assert.Len(t, models, 1)
+	assert.Equal(t, "carol", models[0].Username)
+
+	models, err = repo.FindBy(testMemoryModel{Status: "missing"})
+	assert.NoError(t, err)
+	assert.NotNil(t, models)
+	assert.Empty(t, models)
+
+	_, err = repo.FindBy("status = ?", "active")
+	assert.ErrorIs(t, err, ErrUnsupportedCondition)
+}
+
+func TestMemoryRepository_First(t *testing.T) {
+	repo := testMemoryRepository(t)
+
+	model, err := repo.First(&testMemoryModel{Status: "active"})
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", model.Username)
+
+	_, err = repo.First(&testMemoryModel{Status: "missing"})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestMemoryRepository_Update(t *testing.T) {
+	repo := testMemoryRepository(t)
+
+	model, err := repo.Update(1, UpdateMap{"status": "banned", "Age": "31", "nickname": nil})
+	assert.NoError(t, err)
+	assert.Equal(t, "banned", model.Status)
+	assert.Equal(t, 31, model.Age)
+	assert.Nil(t, model.Nickname)
+	assert.Equal(t, "alice", model.Username)
+
+	found, _ := repo.Find(1)
+	assert.Equal(t, "banned", found.Status)
+
+	_, err = repo.Update(1, UpdateMap{"missing": "value"})
+	assert.Error(t, err)
+
+	_, err = repo.Update(42, UpdateMap{"status": "banned"})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestMemoryRepository_Delete(t *testing.T) {
+	repo := testMemoryRepository(t)
+
+	assert.NoError(t, repo.Delete(1))
+	assert.NoError(t, repo.Delete(42))
+
+	count, err := repo.Count()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	models, _ := repo.FindBy()
+	assert.Equal(t, "bob", models[0].Username)
+}
+
+func TestMemoryRepository_ExistsAndCount(t *testing.T) {
+	repo := testMemoryRepository(t)
+
+	exists, err := repo.Exists(&testMemoryModel{Username: "bob"})
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = repo.Exists(&testMemoryModel{Username: "zoe"})
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	count, err := repo.Count(map[string]interface{}{"status": "active"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestMemoryRepository_Paginate(t *testing.T) {
+	repo := testMemoryRepository(t)
+
+	p := &Paginater{CanPaginate: CanPaginate{Page: 2, Limit: 2}, CanOrder: CanOrder{Sort: "-username"}}
+	models, total, err := repo.Paginate(p, ScopeOptions{Sort: SortRules{"username": {}}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Len(t, models, 1)
+	assert.Equal(t, "alice", models[0].Username)
+
+	p = &Paginater{CanPaginate: CanPaginate{Page: 1, Limit: 10}, CanSearch: CanSearch{Search: "O"}}
+	p.Filters = Filters{{Field: "age", Operator: FilterGte, Values: []string{"25"}}}
+	models, total, err = repo.Paginate(p, ScopeOptions{SearchColumns: []string{"username"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, []string{"bob", "carol"}, []string{models[0].Username, models[1].Username})
+
+	p = &Paginater{CanPaginate: CanPaginate{Page: 1, Limit: 10}, CanOrder: CanOrder{Sort: "nickname"}}
+	models, _, err = repo.Paginate(p, ScopeOptions{Sort: SortRules{"nickname": {Nulls: NullsLast}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", models[0].Username)
+}
+
+func TestMemoryRepository_ShouldBeConcurrencySafe(t *testing.T) {
+	repo := NewMemoryRepository[testMemoryModel]()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			model := &testMemoryModel{Status: "active"}
+			assert.NoError(t, repo.Create(model))
+			_, _ = repo.Update(model.ID, UpdateMap{"status": "banned"})
+			_, _ = repo.FindBy(&testMemoryModel{Status: "banned"})
+		}()
+	}
+	wg.Wait()
+
+	count, err := repo.Count(&testMemoryModel{Status: "banned"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50), count)
+}
@@ -37,6 +37,8 @@ type Prometheus struct {
 	requestsTotal   *prometheus.CounterVec
 	requestDuration *prometheus.HistogramVec
 	requestInFlight *prometheus.GaugeVec
+	healthStatus    *prometheus.GaugeVec
+	cacheRequests   *prometheus.CounterVec
 	defaultURL      string
 }
 
@@ -107,10 +109,24 @@ func create(registry prometheus.Registerer, serviceName, namespace, subsystem st
 		ConstLabels: constLabels,
 	}, []string{"method"})
 
+	health := promauto.With(registry).NewGaugeVec(prometheus.GaugeOpts{
+		Name:        prometheus.BuildFQName(namespace, subsystem, "health_check_status"),
+		Help:        "State of the health checks by name, 1 when passing and 0 when failing.",
+		ConstLabels: constLabels,
+	}, []string{"check"})
+
+	cache := promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Name:        prometheus.BuildFQName(namespace, subsystem, "cache_requests_total"),
+		Help:        "Count all cache lookups by cache name and result (hit or miss).",
+		ConstLabels: constLabels,
+	}, []string{"cache", "result"})
+
 	return &Prometheus{
 		requestsTotal:   counter,
 		requestDuration: histogram,
 		requestInFlight: gauge,
+		healthStatus:    health,
+		cacheRequests:   cache,
 		defaultURL:      "/metrics",
 	}
 }
@@ -160,10 +176,36 @@ func NewWithRegistry(registry prometheus.Registerer, serviceName, namespace, sub
 func (ps *Prometheus) RegisterAt(app *fiber.App, url string, handlers ...fiber.Handler) {
 	ps.defaultURL = url
 
-	h := append(handlers, adaptor.HTTPHandler(promhttp.Handler()))
+	h := append(handlers, ps.Handler())
 	app.Get(ps.defaultURL, h...)
 }
 
+// Handler returns the handler serving the metrics, e.g. to mount it with a custom route.
+func (ps *Prometheus) Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.Handler())
+}
+
+// ObserveHealthCheck exports the state of a health check. Satisfies health.Observer.
+func (ps *Prometheus) ObserveHealthCheck(name string, healthy bool, _ time.Duration) {
+	value := 0.0
+	if healthy {
+		value = 1
+	}
+	ps.healthStatus.WithLabelValues(name).Set(value)
+}
+
+// ObserveCache counts a cache lookup as a hit or a miss. Satisfies napi.CacheObserver. Safe to call on a nil Prometheus.
+func (ps *Prometheus) ObserveCache(name string, hit bool) {
+	if ps == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	ps.cacheRequests.WithLabelValues(name, result).Inc()
+}
+
 // Middleware is the actual default middleware implementation
 func (ps *Prometheus) Middleware(ctx *fiber.Ctx) error {
 	start := time.Now()
@@ -0,0 +1,238 @@
+// Package migrate
+// Versioned database migrations written as Go functions, tracked in the napi_migrations table.
+
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultTable is the table tracking the applied migrations.
+const DefaultTable = "napi_migrations"
+
+var (
+	// ErrIrreversible is returned when rolling back a migration without a Down func.
+	ErrIrreversible = errors.New("migration is irreversible")
+	// ErrUnknownMigration is returned when an applied migration is not registered anymore.
+	ErrUnknownMigration = errors.New("unknown migration")
+)
+
+// Migration is a versioned change to the database schema or data.
+type Migration struct {
+	// ID identifies the migration in the napi_migrations table, e.g. "20221201_create_accounts". Must never change once applied.
+	ID string
+	// Up applies the migration.
+	Up func(tx *gorm.DB) error
+	// Down reverts the migration. Migrations without one cannot be rolled back.
+	Down func(tx *gorm.DB) error
+	// DisableTransaction runs the migration outside of a transaction, e.g. for statements that cannot run in one.
+	DisableTransaction bool
+}
+
+// Record is a row of the napi_migrations table.
+type Record struct {
+	ID        string `gorm:"primaryKey;size:255"`
+	Batch     int
+	AppliedAt time.Time
+}
+
+// Status is the state of a registered migration.
+type Status struct {
+	ID        string     `json:"id"`
+	Applied   bool       `json:"applied"`
+	Batch     int        `json:"batch,omitempty"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// Migrator runs the registered migrations in registration order.
+type Migrator struct {
+	db         *gorm.DB
+	table      string
+	migrations []Migration
+}
+
+// Option type used for option pattern
+type Option func(*Migrator)
+
+// WithTable sets the table tracking the applied migrations. Defaults to napi_migrations.
+func WithTable(table string) Option {
+	return func(m *Migrator) {
+		m.table = table
+	}
+}
+
+// New creates a Migrator for the migrations. Panics when two migrations share an ID or a migration has no ID or Up func.
+//
+//	err := migrate.New(db, migrations).Migrate()
+func New(db *gorm.DB, migrations []Migration, opts ...Option) *Migrator {
+	m := &Migrator{db: db, table: DefaultTable}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m.Register(migrations...)
+}
+
+// Register adds migrations after the already registered ones. Panics when two migrations share an ID or a migration has no ID or Up func.
+func (m *Migrator) Register(migrations ...Migration) *Migrator {
+	for _, migration := range migrations {
+		if migration.ID == "" || migration.Up == nil {
+			panic(fmt.Sprintf("migrate: migration %q must have an ID and an Up func", migration.ID))
+		}
+		if _, ok := m.find(migration.ID); ok {
+			panic(fmt.Sprintf("migrate: duplicate migration %q", migration.ID))
+		}
+		m.migrations = append(m.migrations, migration)
+	}
+	return m
+}
+
+// Migrate applies every pending migration as a new batch. Stops at the first failing migration, keeping the ones applied before it.
+func (m *Migrator) Migrate() error {
+	records, err := m.records()
+	if err != nil {
+		return err
+	}
+
+	batch := 1
+	applied := map[string]bool{}
+	for _, record := range records {
+		applied[record.ID] = true
+		if record.Batch >= batch {
+			batch = record.Batch + 1
+		}
+	}
+
+	for _, migration := range m.migrations {
+		if applied[migration.ID] {
+			continue
+		}
+		migration := migration
+		err = m.run(migration, func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Table(m.table).Create(&Record{ID: migration.ID, Batch: batch, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migrate: %s: %w", migration.ID, err)
+		}
+	}
+	return nil
+}
+
+// Rollback reverts the last steps applied migrations, most recent first.
+func (m *Migrator) Rollback(steps int) error {
+	records, err := m.records()
+	if err != nil {
+		return err
+	}
+
+	for i := len(records) - 1; i >= 0 && steps > 0; i, steps = i-1, steps-1 {
+		id := records[i].ID
+		migration, ok := m.find(id)
+		if !ok {
+			return fmt.Errorf("migrate: %s: %w", id, ErrUnknownMigration)
+		}
+		if migration.Down == nil {
+			return fmt.Errorf("migrate: %s: %w", id, ErrIrreversible)
+		}
+
+		err = m.run(migration, func(tx *gorm.DB) error {
+			if err := migration.Down(tx); err != nil {
+				return err
+			}
+			return tx.Table(m.table).Delete(&Record{ID: id}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migrate: %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// Reset reverts every applied migration.
+func (m *Migrator) Reset() error {
+	records, err := m.records()
+	if err != nil {
+		return err
+	}
+	return m.Rollback(len(records))
+}
+
+// Status lists the registered migrations and whether they have been applied.
+func (m *Migrator) Status() ([]Status, error) {
+	records, err := m.records()
+	if err != nil {
+		return nil, err
+	}
+
+	applied := map[string]Record{}
+	for _, record := range records {
+		applied[record.ID] = record
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i] = Status{ID: migration.ID}
+		if record, ok := applied[migration.ID]; ok {
+			appliedAt := record.AppliedAt
+			statuses[i].Applied = true
+			statuses[i].Batch = record.Batch
+			statuses[i].AppliedAt = &appliedAt
+		}
+	}
+	return statuses, nil
+}
+
+// run runs fn in a transaction, unless the migration or the dialect does not allow it.
+func (m *Migrator) run(migration Migration, fn func(tx *gorm.DB) error) error {
+	if migration.DisableTransaction || !m.transactionalDDL() {
+		return fn(m.db)
+	}
+	return m.db.Transaction(fn)
+}
+
+// transactionalDDL checks if schema changes can be rolled back. MySQL commits DDL statements implicitly.
+func (m *Migrator) transactionalDDL() bool {
+	return m.db.Dialector.Name() != "mysql"
+}
+
+// records creates the migrations table if needed and lists the applied migrations, in the order they were applied.
+func (m *Migrator) records() ([]Record, error) {
+	if err := m.db.Table(m.table).AutoMigrate(&Record{}); err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	if err := m.db.Table(m.table).Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	// sort by batch, then by registration order within a batch
+	order := map[string]int{}
+	for i, migration := range m.migrations {
+		order[migration.ID] = i
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Batch != records[j].Batch {
+			return records[i].Batch < records[j].Batch
+		}
+		return order[records[i].ID] < order[records[j].ID]
+	})
+	return records, nil
+}
+
+// find gets a registered migration by ID.
+func (m *Migrator) find(id string) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.ID == id {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
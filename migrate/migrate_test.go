@@ -0,0 +1,175 @@
+package migrate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	glog "gorm.io/gorm/logger"
+)
+
+type testAccount struct {
+	ID       uint
+	Username string
+}
+
+func testDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: glog.Default.LogMode(glog.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func testMigrations() []Migration {
+	return []Migration{
+		{
+			ID: "001_create_accounts",
+			Up: func(tx *gorm.DB) error {
+				return tx.Migrator().CreateTable(&testAccount{})
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable(&testAccount{})
+			},
+		},
+		{
+			ID: "002_seed_admin",
+			Up: func(tx *gorm.DB) error {
+				return tx.Create(&testAccount{Username: "admin"}).Error
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Where("username = ?", "admin").Delete(&testAccount{}).Error
+			},
+		},
+	}
+}
+
+func testStatuses(t *testing.T, m *Migrator) []bool {
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied := make([]bool, len(statuses))
+	for i, status := range statuses {
+		applied[i] = status.Applied
+	}
+	return applied
+}
+
+func TestMigrator_Migrate(t *testing.T) {
+	db := testDB(t)
+	m := New(db, testMigrations())
+
+	assert.Equal(t, []bool{false, false}, testStatuses(t, m))
+	assert.NoError(t, m.Migrate())
+	assert.NoError(t, m.Migrate())
+
+	var count int64
+	db.Model(&testAccount{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	statuses, err := m.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, "001_create_accounts", statuses[0].ID)
+	assert.True(t, statuses[1].Applied)
+	assert.Equal(t, 1, statuses[1].Batch)
+	assert.NotNil(t, statuses[1].AppliedAt)
+	assert.True(t, db.Migrator().HasTable(DefaultTable))
+}
+
+func TestMigrator_ShouldRunNewMigrationsAsNextBatch(t *testing.T) {
+	db := testDB(t)
+	migrations := testMigrations()
+	assert.NoError(t, New(db, migrations[:1]).Migrate())
+
+	m := New(db, migrations)
+	assert.NoError(t, m.Migrate())
+
+	statuses, _ := m.Status()
+	assert.Equal(t, 1, statuses[0].Batch)
+	assert.Equal(t, 2, statuses[1].Batch)
+}
+
+func TestMigrator_ShouldRollbackFailedMigration(t *testing.T) {
+	db := testDB(t)
+	failed := errors.New("failed")
+	m := New(db, testMigrations()).Register(Migration{
+		ID: "003_broken",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Create(&testAccount{Username: "broken"}).Error; err != nil {
+				return err
+			}
+			return failed
+		},
+	})
+
+	err := m.Migrate()
+	assert.ErrorIs(t, err, failed)
+	assert.Contains(t, err.Error(), "003_broken")
+	assert.Equal(t, []bool{true, true, false}, testStatuses(t, m))
+
+	var count int64
+	db.Model(&testAccount{}).Where("username = ?", "broken").Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestMigrator_Rollback(t *testing.T) {
+	db := testDB(t)
+	m := New(db, testMigrations())
+	assert.NoError(t, m.Migrate())
+
+	assert.NoError(t, m.Rollback(1))
+	assert.Equal(t, []bool{true, false}, testStatuses(t, m))
+	assert.True(t, db.Migrator().HasTable(&testAccount{}))
+
+	assert.NoError(t, m.Rollback(5))
+	assert.Equal(t, []bool{false, false}, testStatuses(t, m))
+	assert.False(t, db.Migrator().HasTable(&testAccount{}))
+}
+
+func TestMigrator_Reset(t *testing.T) {
+	db := testDB(t)
+	m := New(db, testMigrations())
+	assert.NoError(t, m.Migrate())
+
+	assert.NoError(t, m.Reset())
+	assert.Equal(t, []bool{false, false}, testStatuses(t, m))
+
+	assert.NoError(t, m.Migrate())
+	assert.Equal(t, []bool{true, true}, testStatuses(t, m))
+}
+
+func TestMigrator_RollbackErrors(t *testing.T) {
+	db := testDB(t)
+	m := New(db, []Migration{{ID: "001_irreversible", Up: func(tx *gorm.DB) error { return nil }}})
+	assert.NoError(t, m.Migrate())
+	assert.ErrorIs(t, m.Rollback(1), ErrIrreversible)
+
+	assert.ErrorIs(t, New(db, testMigrations()).Rollback(1), ErrUnknownMigration)
+}
+
+func TestMigrator_Register_ShouldPanicOnDuplicates(t *testing.T) {
+	assert.Panics(t, func() {
+		New(nil, append(testMigrations(), testMigrations()[0]))
+	})
+	assert.Panics(t, func() {
+		New(nil, []Migration{{ID: "missing_up"}})
+	})
+}
+
+func TestWithTable(t *testing.T) {
+	db := testDB(t)
+	assert.NoError(t, New(db, testMigrations(), WithTable("schema_versions")).Migrate())
+
+	assert.True(t, db.Migrator().HasTable("schema_versions"))
+	assert.False(t, db.Migrator().HasTable(DefaultTable))
+}
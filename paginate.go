@@ -1,5 +1,7 @@
 package napi
 
+import "github.com/netr/napi/resp"
+
 type Paginater struct {
 	CanPaginate
 	CanOrder
@@ -31,10 +33,14 @@ type CanPaginate struct {
 type CanOrder struct {
 	OrderBy  string `query:"order_by"`
 	OrderDir string `query:"order_dir"`
+	// Sort multi-column sorting, e.g. `-created_at,username`. Takes precedence over order_by and order_dir.
+	Sort string `query:"sort"`
 }
 
 type CanFilter struct {
 	FilterBy string `query:"filter_by"`
+	// Filters the structured `filter[field][operator]=value` conditions, set by ParseFilters.
+	Filters Filters `query:"-" json:"-"`
 }
 
 type CanSearch struct {
@@ -45,6 +51,11 @@ func (req *CanPaginate) Offset() int {
 	return (req.Page - 1) * req.Limit
 }
 
+// Meta builds the resp.PaginationMeta of the current page, to be sent with resp.Sender.Paginated.
+func (req *CanPaginate) Meta(total int64) resp.PaginationMeta {
+	return resp.NewPaginationMeta(req.Page, req.Limit, total)
+}
+
 func (req *CanPaginate) ValidatePagination(page, limit int) {
 	if req.Page < 1 {
 		req.Page = page
@@ -68,3 +68,14 @@ func TestTableManager_ValidateOrder_ShouldSetDefaultsProperly(t *testing.T) {
 	assert.Equal(t, "test", cp.OrderBy)
 	assert.Equal(t, "desc", cp.OrderDir)
 }
+
+func TestCanPaginate_Meta_ShouldUseCurrentPage(t *testing.T) {
+	cp := new(TestRequest)
+	cp.ValidatePagination(2, 10)
+
+	meta := cp.Meta(15)
+
+	assert.Equal(t, 2, meta.LastPage)
+	assert.Equal(t, 11, meta.From)
+	assert.Equal(t, 15, meta.To)
+}
@@ -2,6 +2,7 @@ package napi
 
 import (
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type IDatabaseDriver[T any] interface {
@@ -34,7 +35,7 @@ func (r GormRepository) DB() *gorm.DB {
 }
 
 func (r GormRepository) Find(model interface{}, conds ...interface{}) (interface{}, error) {
-	tx := r.db.Find(&model, conds...)
+	tx := r.db.Find(model, conds...)
 	return model, tx.Error
 }
 
@@ -56,9 +57,128 @@ func (r GormRepository) Update(model interface{}, id interface{}, values map[str
 }
 
 func (r GormRepository) Exists(model interface{}, query interface{}, args ...interface{}) bool {
-	if tx := r.db.Where(query, args).First(&model); tx.Error != nil {
+	if tx := r.db.Where(query, args...).First(model); tx.Error != nil {
 		return false
 	} else {
 		return tx.RowsAffected > 0
 	}
 }
+
+// Repository is a type-safe repository for the model T. Conditions follow gorm's inline conditions: a primary key, a struct, a map, or a query string followed by its args.
+type Repository[T any] interface {
+	Find(id interface{}) (*T, error)
+	FindBy(conds ...interface{}) ([]T, error)
+	First(conds ...interface{}) (*T, error)
+	Create(model *T) error
+	CreateMany(models []T) error
+	Update(id interface{}, values UpdateMap) (*T, error)
+	Delete(id interface{}) error
+	Exists(conds ...interface{}) (bool, error)
+	Count(conds ...interface{}) (int64, error)
+	Paginate(p *Paginater, opts ScopeOptions) ([]T, int64, error)
+}
+
+// GormRepositoryOf implements Repository[T] on top of a gorm driver.
+type GormRepositoryOf[T any] struct {
+	driver IDatabaseDriver[*gorm.DB]
+}
+
+// NewRepository creates a Repository[T] using the driver's *gorm.DB, e.g. NewRepository[Account](NewGormRepository(db)).
+func NewRepository[T any](driver IDatabaseDriver[*gorm.DB]) *GormRepositoryOf[T] {
+	return &GormRepositoryOf[T]{driver: driver}
+}
+
+// DB returns the underlying *gorm.DB
+func (r GormRepositoryOf[T]) DB() *gorm.DB {
+	return r.driver.DB()
+}
+
+// Find gets a model by primary key. Returns gorm.ErrRecordNotFound if it does not exist.
+func (r GormRepositoryOf[T]) Find(id interface{}) (*T, error) {
+	model := new(T)
+	if tx := r.DB().Where(byPrimaryKey(id)).First(model); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return model, nil
+}
+
+// FindBy gets every model matching the conditions, or every model without conditions.
+func (r GormRepositoryOf[T]) FindBy(conds ...interface{}) ([]T, error) {
+	models := make([]T, 0)
+	if tx := r.DB().Find(&models, conds...); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return models, nil
+}
+
+// First gets the first model, ordered by primary key, matching the conditions. Returns gorm.ErrRecordNotFound if none match.
+func (r GormRepositoryOf[T]) First(conds ...interface{}) (*T, error) {
+	model := new(T)
+	if tx := r.DB().First(model, conds...); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return model, nil
+}
+
+// Create inserts the model and fills in its primary key and defaults.
+func (r GormRepositoryOf[T]) Create(model *T) error {
+	return r.DB().Create(model).Error
+}
+
+// CreateMany inserts the models in a single batch.
+func (r GormRepositoryOf[T]) CreateMany(models []T) error {
+	if len(models) == 0 {
+		return nil
+	}
+	return r.DB().Create(&models).Error
+}
+
+// Update updates the columns of a model by primary key and returns the updated model. Returns gorm.ErrRecordNotFound if it does not exist.
+func (r GormRepositoryOf[T]) Update(id interface{}, values UpdateMap) (*T, error) {
+	model, err := r.Find(id)
+	if err != nil {
+		return nil, err
+	}
+	if tx := r.DB().Model(model).Updates(map[string]interface{}(values)); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return model, nil
+}
+
+// Delete deletes a model by primary key.
+func (r GormRepositoryOf[T]) Delete(id interface{}) error {
+	return r.DB().Where(byPrimaryKey(id)).Delete(new(T)).Error
+}
+
+// Exists checks if any model matches the conditions.
+func (r GormRepositoryOf[T]) Exists(conds ...interface{}) (bool, error) {
+	var exists bool
+	tx := r.where(conds).Select("1").Limit(1).Find(&exists)
+	return tx.RowsAffected > 0, tx.Error
+}
+
+// Count counts the models matching the conditions.
+func (r GormRepositoryOf[T]) Count(conds ...interface{}) (int64, error) {
+	var count int64
+	tx := r.where(conds).Count(&count)
+	return count, tx.Error
+}
+
+// Paginate lists a page of models and counts every match. See Paginate.
+func (r GormRepositoryOf[T]) Paginate(p *Paginater, opts ScopeOptions) ([]T, int64, error) {
+	return Paginate[T](r.DB(), p, opts)
+}
+
+// byPrimaryKey binds the id as a value of the primary key. Unlike gorm's inline conditions, a string id is never read as SQL.
+func byPrimaryKey(id interface{}) clause.Eq {
+	return clause.Eq{Column: clause.PrimaryColumn, Value: id}
+}
+
+// where applies inline conditions to a query on the model's table.
+func (r GormRepositoryOf[T]) where(conds []interface{}) *gorm.DB {
+	tx := r.DB().Model(new(T))
+	if len(conds) > 0 {
+		tx = tx.Where(conds[0], conds[1:]...)
+	}
+	return tx
+}
@@ -0,0 +1,151 @@
+package napi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testRepoModel struct {
+	ID       uint
+	Username string `gorm:"unique"`
+	Status   string
+}
+
+var _ Repository[testRepoModel] = NewRepository[testRepoModel](nil)
+
+func testRepository(t *testing.T) *GormRepositoryOf[testRepoModel] {
+	repo := NewRepository[testRepoModel](NewGormRepository(testGormDB(t, &testRepoModel{})))
+	if err := repo.CreateMany([]testRepoModel{
+		{Username: "alice", Status: "active"},
+		{Username: "bob", Status: "banned"},
+		{Username: "carol", Status: "active"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestRepository_Find(t *testing.T) {
+	repo := testRepository(t)
+
+	model, err := repo.Find(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", model.Username)
+
+	_, err = repo.Find(42)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestRepository_FindBy(t *testing.T) {
+	repo := testRepository(t)
+
+	models, err := repo.FindBy(&testRepoModel{Status: "active"})
+	assert.NoError(t, err)
+	assert.Len(t, models, 2)
+
+	models, err = repo.FindBy("username LIKE ?", "%o%")
+	assert.NoError(t, err)
+	assert.Len(t, models, 2)
+
+	models, err = repo.FindBy("status = ?", "missing")
+	assert.NoError(t, err)
+	assert.NotNil(t, models)
+	assert.Empty(t, models)
+}
+
+func TestRepository_First(t *testing.T) {
+	repo := testRepository(t)
+
+	model, err := repo.First("status = ?", "active")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", model.Username)
+}
+
+func TestRepository_Create(t *testing.T) {
+	repo := testRepository(t)
+
+	model := &testRepoModel{Username: "dave"}
+	assert.NoError(t, repo.Create(model))
+	assert.Equal(t, uint(4), model.ID)
+
+	assert.Error(t, repo.Create(&testRepoModel{Username: "dave"}))
+}
+
+func TestRepository_Update(t *testing.T) {
+	repo := testRepository(t)
+
+	model, err := repo.Update(1, UpdateMap{"status": "banned"})
+	assert.NoError(t, err)
+	assert.Equal(t, "banned", model.Status)
+	assert.Equal(t, "alice", model.Username)
+
+	found, _ := repo.Find(1)
+	assert.Equal(t, "banned", found.Status)
+
+	_, err = repo.Update(42, UpdateMap{"status": "banned"})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestRepository_Delete(t *testing.T) {
+	repo := testRepository(t)
+
+	assert.NoError(t, repo.Delete(1))
+	count, err := repo.Count()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestRepository_ShouldBindTheID(t *testing.T) {
+	repo := testRepository(t)
+
+	_, err := repo.Find("1=1")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	_, err = repo.Update("1=1", UpdateMap{"status": "banned"})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	assert.NoError(t, repo.Delete("1=1"))
+	count, err := repo.Count()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	banned, err := repo.Count("status = ?", "banned")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), banned)
+}
+
+func TestRepository_ExistsAndCount(t *testing.T) {
+	repo := testRepository(t)
+
+	exists, err := repo.Exists("username = ?", "bob")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	exists, err = repo.Exists(&testRepoModel{Username: "zoe"})
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	count, err := repo.Count(map[string]interface{}{"status": "active"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestRepository_Paginate(t *testing.T) {
+	repo := testRepository(t)
+	p := &Paginater{CanPaginate: CanPaginate{Page: 2, Limit: 2}, CanOrder: CanOrder{Sort: "-username"}}
+
+	models, total, err := repo.Paginate(p, ScopeOptions{Sort: SortRules{"username": {}}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, "alice", models[0].Username)
+}
+
+func TestGormRepository_Exists_ShouldPassArgs(t *testing.T) {
+	repo := NewGormRepository(testGormDB(t, &testRepoModel{}))
+	assert.NoError(t, repo.Create(&testRepoModel{Username: "alice"}))
+
+	assert.True(t, repo.Exists(&testRepoModel{}, "username = ?", "alice"))
+	assert.False(t, repo.Exists(&testRepoModel{}, "username = ?", "bob"))
+}
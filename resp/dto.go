@@ -23,3 +23,55 @@ type FormErrorResponse struct {
 	Errors  ErrorBag `json:"errors"`
 	Message string   `json:"message"`
 }
+
+// ProblemDetails is an RFC 7807 problem document, sent as application/problem+json.
+// @Description Failed API responses in the RFC 7807 format.
+// @Description Extensions are merged into the top level members.
+type ProblemDetails struct {
+	Type          string                 `json:"type"`
+	Title         string                 `json:"title"`
+	Status        int                    `json:"status"`
+	Detail        string                 `json:"detail,omitempty"`
+	Instance      string                 `json:"instance,omitempty"`
+	InvalidParams []InvalidParam         `json:"invalid-params,omitempty"`
+	Extensions    map[string]interface{} `json:"-"`
+}
+
+// InvalidParam describes a single failed validation in a ProblemDetails document.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// PaginatedResponse wraps a page of return data with a message and pagination meta
+// @Description Wrap paginated API responses with a message, data and meta.
+type PaginatedResponse struct {
+	Data    interface{}    `json:"data"`
+	Meta    PaginationMeta `json:"meta"`
+	Message string         `json:"message"`
+}
+
+// PaginationMeta describes the current page of a PaginatedResponse. From and To are the 1-based positions of the first and last items, 0 when the page is empty.
+type PaginationMeta struct {
+	Page     int   `json:"page"`
+	Limit    int   `json:"limit"`
+	Total    int64 `json:"total"`
+	LastPage int   `json:"last_page"`
+	From     int   `json:"from"`
+	To       int   `json:"to"`
+}
+
+// CursorPaginatedResponse wraps a keyset page of return data with a message and cursor meta
+// @Description Wrap cursor paginated API responses with a message, data and meta.
+type CursorPaginatedResponse struct {
+	Data    interface{} `json:"data"`
+	Meta    CursorMeta  `json:"meta"`
+	Message string      `json:"message"`
+}
+
+// CursorMeta holds the opaque tokens of the next and previous pages, empty when there is no such page.
+type CursorMeta struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor"`
+	PrevCursor string `json:"prev_cursor"`
+}
@@ -0,0 +1,375 @@
+package resp
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	// MIMEApplicationMsgpack is the media type of MessagePack responses.
+	MIMEApplicationMsgpack = "application/msgpack"
+	// MIMEApplicationXMsgpack is the legacy media type of MessagePack responses.
+	MIMEApplicationXMsgpack = "application/x-msgpack"
+	// MIMETextCSV is the media type of CSV responses.
+	MIMETextCSV = "text/csv"
+)
+
+// Encoder encodes a response body, e.g. a SuccessResponse, for a media type.
+type Encoder func(v interface{}) ([]byte, error)
+
+// encoderRegistry holds the encoders in registration order. The first one is used when the client accepts anything.
+type encoderRegistry struct {
+	lock       *sync.RWMutex
+	mediaTypes []string
+	encoders   map[string]Encoder
+}
+
+var encoders = &encoderRegistry{
+	lock:     new(sync.RWMutex),
+	encoders: map[string]Encoder{},
+}
+
+// init registers JSON without an encoder, so JSON responses use the app's fiber.Config.JSONEncoder.
+func init() {
+	RegisterEncoder(fiber.MIMEApplicationJSON, nil)
+	RegisterEncoder(fiber.MIMEApplicationXML, EncodeXML)
+	RegisterEncoder(fiber.MIMETextXML, EncodeXML)
+	RegisterEncoder(MIMEApplicationMsgpack, EncodeMsgpack)
+	RegisterEncoder(MIMEApplicationXMsgpack, EncodeMsgpack)
+	RegisterEncoder(MIMETextCSV, EncodeCSV)
+}
+
+// RegisterEncoder adds an encoder for a media type, or replaces the existing one. Sender picks the encoder matching the Accept header, JSON being the default.
+// A nil encoder uses the app's fiber.Config.JSONEncoder.
+func RegisterEncoder(mediaType string, enc Encoder) {
+	encoders.lock.Lock()
+	defer encoders.lock.Unlock()
+
+	if _, ok := encoders.encoders[mediaType]; !ok {
+		encoders.mediaTypes = append(encoders.mediaTypes, mediaType)
+	}
+	encoders.encoders[mediaType] = enc
+}
+
+// negotiate finds the encoder for the best media type accepted by the client.
+func (e *encoderRegistry) negotiate(c *fiber.Ctx) (string, Encoder, bool) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	mediaType := c.Accepts(e.mediaTypes...)
+	if mediaType == "" {
+		return "", nil, false
+	}
+	return mediaType, e.encoders[mediaType], true
+}
+
+// supported lists the registered media types.
+func (e *encoderRegistry) supported() []string {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	return append([]string(nil), e.mediaTypes...)
+}
+
+// EncodeMsgpack encodes MessagePack using the json struct tags, so keys match the JSON responses.
+func EncodeMsgpack(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodeXML encodes XML with a <response> root element. Element names follow the json struct tags, and slice items are <item> elements.
+// Keys that are not valid element names, e.g. starting with a digit or holding a space or a colon, are <entry key="..."> elements.
+func EncodeXML(v interface{}) ([]byte, error) {
+	generic, err := toGeneric(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	if err = encodeXMLElement(enc, "response", generic); err != nil {
+		return nil, err
+	}
+	if err = enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// EncodeCSV encodes CSV with a header row. Only the data of success and paginated responses is encoded. Slices become one row per item and nested structs are flattened into dotted column names.
+func EncodeCSV(v interface{}) ([]byte, error) {
+	switch res := v.(type) {
+	case SuccessResponse:
+		v = res.Data
+	case *SuccessResponse:
+		v = res.Data
+	case PaginatedResponse:
+		v = res.Data
+	case *PaginatedResponse:
+		v = res.Data
+	case CursorPaginatedResponse:
+		v = res.Data
+	case *CursorPaginatedResponse:
+		v = res.Data
+	}
+
+	var rows []*csvRow
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.IsValid() && (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && !isMarshaler(rv) {
+		for i := 0; i < rv.Len(); i++ {
+			row := newCSVRow()
+			if err := flattenCSV(row, "", rv.Index(i)); err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
+	} else if rv.IsValid() {
+		row := newCSVRow()
+		if err := flattenCSV(row, "", rv); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+
+	// union of the columns, in order of first appearance
+	var columns []string
+	seen := map[string]bool{}
+	for _, row := range rows {
+		for _, col := range row.columns {
+			if !seen[col] {
+				seen[col] = true
+				columns = append(columns, col)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if len(columns) > 0 {
+		if err := w.Write(columns); err != nil {
+			return nil, err
+		}
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, col := range columns {
+			record[i] = row.values[col]
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// toGeneric converts a value into maps, slices and scalars through a JSON round trip, so the json struct tags are respected.
+func toGeneric(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err = dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// encodeXMLElement writes a generic value as an element. Map keys are sorted to keep the output stable.
+func encodeXMLElement(enc *xml.Encoder, name string, v interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	if !isXMLName(name) {
+		start = xml.StartElement{
+			Name: xml.Name{Local: "entry"},
+			Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}},
+		}
+	}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := encodeXMLElement(enc, k, val[k]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range val {
+			if err := encodeXMLElement(enc, "item", item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		if err := enc.EncodeToken(xml.CharData(fmt.Sprintf("%v", val))); err != nil {
+			return err
+		}
+	}
+
+	return enc.EncodeToken(start.End())
+}
+
+// isXMLName reports whether name can be used as an element name as is. Colons are left out, as they would declare a namespace prefix.
+func isXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case i > 0 && (r == '-' || r == '.' || unicode.IsDigit(r)):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// csvRow is a flattened row, keeping the column order.
+type csvRow struct {
+	columns []string
+	values  map[string]string
+}
+
+func newCSVRow() *csvRow {
+	return &csvRow{values: map[string]string{}}
+}
+
+func (r *csvRow) set(column, value string) {
+	if _, ok := r.values[column]; !ok {
+		r.columns = append(r.columns, column)
+	}
+	r.values[column] = value
+}
+
+// flattenCSV flattens structs and maps into dotted columns. Anything else is a single cell.
+func flattenCSV(row *csvRow, prefix string, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			row.set(csvColumn(prefix, "value"), "")
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if isCSVLeaf(v) {
+		cell, err := csvCell(v)
+		if err != nil {
+			return err
+		}
+		row.set(csvColumn(prefix, "value"), cell)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if field.Anonymous && name == "" {
+				if err := flattenCSV(row, prefix, v.Field(i)); err != nil {
+					return err
+				}
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			if err := flattenCSV(row, csvColumn(prefix, name), v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			if err := flattenCSV(row, csvColumn(prefix, fmt.Sprint(k.Interface())), v.MapIndex(k)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isCSVLeaf checks if a value is written as a single cell. Slices nested in a row are written as JSON.
+func isCSVLeaf(v reflect.Value) bool {
+	return isMarshaler(v) || (v.Kind() != reflect.Struct && v.Kind() != reflect.Map)
+}
+
+// isMarshaler checks if a value formats itself, e.g. time.Time.
+func isMarshaler(v reflect.Value) bool {
+	if !v.CanInterface() {
+		return false
+	}
+	switch v.Interface().(type) {
+	case json.Marshaler, encoding.TextMarshaler:
+		return true
+	}
+	return false
+}
+
+// csvCell formats a single cell. Strings are written as is, anything else as JSON.
+func csvCell(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.String {
+		return v.String(), nil
+	}
+
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return "", err
+	}
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		return s, nil
+	}
+	return string(b), nil
+}
+
+// csvColumn joins nested column names with a dot.
+func csvColumn(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	if name == "value" {
+		return prefix
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,176 @@
+package resp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type testUser struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Address struct {
+		City string `json:"city"`
+	} `json:"address"`
+	Tags   []string `json:"tags"`
+	secret string
+}
+
+func testUsers() []testUser {
+	users := []testUser{{ID: 1, Name: "alice", Tags: []string{"a", "b"}}, {ID: 2, Name: "bob, jr"}}
+	users[0].Address.City = "Paris"
+	return users
+}
+
+func newTestNegotiationApp() *fiber.App {
+	app := fiber.New()
+	app.Get("/users", func(c *fiber.Ctx) error {
+		return New(c).Success("users", testUsers())
+	})
+	app.Get("/error", func(c *fiber.Ctx) error {
+		return New(c).Error("failed", errors.New("boom"))
+	})
+	return app
+}
+
+func newTestAcceptRequest(app *fiber.App, target, accept string) (*http.Response, error) {
+	req := httptest.NewRequest("GET", target, nil)
+	req.Header.Set(fiber.HeaderAccept, accept)
+	return app.Test(req, 15000)
+}
+
+func TestSender_ShouldDefaultToJson(t *testing.T) {
+	resp, err := newTestRequest(newTestNegotiationApp(), "GET", "/users", nil)
+	handleError(t, err)
+	body, err := responseToString(resp)
+	handleError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get(fiber.HeaderContentType))
+	assert.Contains(t, body, `"name":"alice"`)
+}
+
+func TestSender_ShouldUseTheAppJsonEncoder(t *testing.T) {
+	app := fiber.New(fiber.Config{JSONEncoder: func(v interface{}) ([]byte, error) {
+		return []byte(`{"encoder":"custom"}`), nil
+	}})
+	app.Get("/users", func(c *fiber.Ctx) error {
+		return New(c).Success("users", testUsers())
+	})
+
+	resp, err := newTestAcceptRequest(app, "/users", fiber.MIMEApplicationJSON)
+	handleError(t, err)
+	body, err := responseToString(resp)
+	handleError(t, err)
+
+	assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get(fiber.HeaderContentType))
+	assert.Equal(t, `{"encoder":"custom"}`, body)
+}
+
+func TestSender_ShouldNegotiateXml(t *testing.T) {
+	resp, err := newTestAcceptRequest(newTestNegotiationApp(), "/users", "application/xml")
+	handleError(t, err)
+	body, err := responseToString(resp)
+	handleError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, fiber.MIMEApplicationXML, resp.Header.Get(fiber.HeaderContentType))
+	assert.Contains(t, body, "<response><data><item><address><city>Paris</city></address><id>1</id><name>alice</name><tags><item>a</item><item>b</item></tags></item>")
+	assert.Contains(t, body, "<message>users</message></response>")
+}
+
+func TestSender_ShouldNegotiateMsgpack(t *testing.T) {
+	resp, err := newTestAcceptRequest(newTestNegotiationApp(), "/users", "application/x-msgpack")
+	handleError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, MIMEApplicationXMsgpack, resp.Header.Get(fiber.HeaderContentType))
+
+	var res map[string]interface{}
+	handleError(t, msgpack.NewDecoder(resp.Body).Decode(&res))
+	assert.Equal(t, "users", res["message"])
+	assert.Len(t, res["data"], 2)
+}
+
+func TestSender_ShouldNegotiateCsv(t *testing.T) {
+	resp, err := newTestAcceptRequest(newTestNegotiationApp(), "/users", "text/csv")
+	handleError(t, err)
+	body, err := responseToString(resp)
+	handleError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, MIMETextCSV, resp.Header.Get(fiber.HeaderContentType))
+	assert.Equal(t, "id,name,address.city,tags\n1,alice,Paris,\"[\"\"a\"\",\"\"b\"\"]\"\n2,\"bob, jr\",,\n", body)
+}
+
+func TestSender_ShouldNegotiateErrors(t *testing.T) {
+	resp, err := newTestAcceptRequest(newTestNegotiationApp(), "/error", "application/xml")
+	handleError(t, err)
+	body, err := responseToString(resp)
+	handleError(t, err)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	assert.Contains(t, body, "<response><error>boom</error><message>failed</message></response>")
+}
+
+func TestSender_ShouldReturnNotAcceptable(t *testing.T) {
+	resp, err := newTestAcceptRequest(newTestNegotiationApp(), "/users", "image/png")
+	handleError(t, err)
+	body, err := responseToString(resp)
+	handleError(t, err)
+
+	assert.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
+	assert.Contains(t, body, "text/csv")
+}
+
+func TestRegisterEncoder_ShouldAddMediaType(t *testing.T) {
+	RegisterEncoder("text/plain", func(v interface{}) ([]byte, error) {
+		return []byte(v.(SuccessResponse).Message), nil
+	})
+
+	resp, err := newTestAcceptRequest(newTestNegotiationApp(), "/users", "text/plain")
+	handleError(t, err)
+	body, err := responseToString(resp)
+	handleError(t, err)
+
+	assert.Equal(t, "users", body)
+}
+
+func TestEncodeCSV_ShouldEncodeSingleStruct(t *testing.T) {
+	b, err := EncodeCSV(testUsers()[0])
+	handleError(t, err)
+
+	assert.Equal(t, "id,name,address.city,tags\n1,alice,Paris,\"[\"\"a\"\",\"\"b\"\"]\"\n", string(b))
+}
+
+func TestEncodeXML_ShouldUseEntriesForInvalidNames(t *testing.T) {
+	b, err := EncodeXML(map[string]interface{}{"1st": 1, "full name": "alice", "a:b": "c", "<x>": "y", "ok": true})
+	handleError(t, err)
+
+	assert.Contains(t, string(b), `<response><entry key="1st">1</entry><entry key="&lt;x&gt;">y</entry><entry key="a:b">c</entry><entry key="full name">alice</entry><ok>true</ok></response>`)
+
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		if _, err = dec.Token(); err != nil {
+			break
+		}
+	}
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestEncodeMsgpack_ShouldUseJsonTags(t *testing.T) {
+	b, err := EncodeMsgpack(testUsers()[0])
+	handleError(t, err)
+
+	var res map[string]interface{}
+	handleError(t, msgpack.NewDecoder(bytes.NewReader(b)).Decode(&res))
+	assert.Equal(t, "alice", res["name"])
+}
@@ -0,0 +1,104 @@
+package resp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// PageQueryKey is the query parameter holding the page number in Link header URLs.
+	PageQueryKey = "page"
+	// CursorQueryKey is the query parameter holding the cursor token in Link header URLs.
+	CursorQueryKey = "cursor"
+)
+
+// NewPaginationMeta computes the last page and the From/To item positions for the given page.
+func NewPaginationMeta(page, limit int, total int64) PaginationMeta {
+	if page < 1 {
+		page = 1
+	}
+	meta := PaginationMeta{Page: page, Limit: limit, Total: total, LastPage: 1}
+	if limit < 1 || total < 1 {
+		return meta
+	}
+
+	meta.LastPage = int((total + int64(limit) - 1) / int64(limit))
+	offset := int64(page-1) * int64(limit)
+	if offset >= total {
+		return meta
+	}
+
+	meta.From = int(offset) + 1
+	meta.To = int(offset) + limit
+	if int64(meta.To) > total {
+		meta.To = int(total)
+	}
+	return meta
+}
+
+// Paginated sends a page of data with its PaginationMeta and a http.StatusOK status code. Sets RFC 8288 Link headers for the first, prev, next and last pages.
+func (r Sender) Paginated(msg string, data interface{}, meta PaginationMeta) error {
+	if link := r.paginationLinks(meta); link != "" {
+		r.ctx.Set(fiber.HeaderLink, link)
+	}
+
+	return r.send(http.StatusOK, PaginatedResponse{
+		Message: msg,
+		Data:    normalizeData(data),
+		Meta:    meta,
+	})
+}
+
+// CursorPaginated sends a keyset page of data with its CursorMeta and a http.StatusOK status code. Sets RFC 8288 Link headers for the next and prev pages.
+func (r Sender) CursorPaginated(msg string, data interface{}, meta CursorMeta) error {
+	var links []string
+	if meta.PrevCursor != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, r.linkURL(CursorQueryKey, meta.PrevCursor)))
+	}
+	if meta.NextCursor != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, r.linkURL(CursorQueryKey, meta.NextCursor)))
+	}
+	if len(links) > 0 {
+		r.ctx.Set(fiber.HeaderLink, strings.Join(links, ", "))
+	}
+
+	return r.send(http.StatusOK, CursorPaginatedResponse{
+		Message: msg,
+		Data:    normalizeData(data),
+		Meta:    meta,
+	})
+}
+
+// paginationLinks builds the Link header value from the current URL, replacing the page query parameter and keeping the others.
+func (r Sender) paginationLinks(meta PaginationMeta) string {
+	pageURL := func(page int) string {
+		return r.linkURL(PageQueryKey, strconv.Itoa(page))
+	}
+
+	links := []string{fmt.Sprintf(`<%s>; rel="first"`, pageURL(1))}
+	if meta.Page > 1 && meta.Page <= meta.LastPage {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(meta.Page-1)))
+	}
+	if meta.Page < meta.LastPage {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(meta.Page+1)))
+	}
+	links = append(links, fmt.Sprintf(`<%s>; rel="last"`, pageURL(meta.LastPage)))
+
+	return strings.Join(links, ", ")
+}
+
+// linkURL builds the absolute URL of the current request with a replaced query parameter.
+func (r Sender) linkURL(key, value string) string {
+	u, err := url.Parse(r.ctx.OriginalURL())
+	if err != nil {
+		return ""
+	}
+	query := u.Query()
+	query.Set(key, value)
+	return r.ctx.BaseURL() + u.Path + "?" + query.Encode()
+}
@@ -0,0 +1,80 @@
+package resp
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPaginationMeta(t *testing.T) {
+	tests := []struct {
+		name               string
+		page, limit        int
+		total              int64
+		lastPage, from, to int
+	}{
+		{"first page", 1, 10, 25, 3, 1, 10},
+		{"last partial page", 3, 10, 25, 3, 21, 25},
+		{"page past the end", 4, 10, 25, 3, 0, 0},
+		{"no results", 1, 10, 0, 1, 0, 0},
+		{"exact pages", 2, 5, 10, 2, 6, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := NewPaginationMeta(tt.page, tt.limit, tt.total)
+			assert.Equal(t, PaginationMeta{Page: tt.page, Limit: tt.limit, Total: tt.total, LastPage: tt.lastPage, From: tt.from, To: tt.to}, meta)
+		})
+	}
+}
+
+func TestSender_Paginated_ExpectedBehavior(t *testing.T) {
+	app := fiber.New()
+	app.Get("/users", func(c *fiber.Ctx) error {
+		return New(c).Paginated("users", []string{"c", "d"}, NewPaginationMeta(2, 2, 5))
+	})
+
+	resp, err := newTestRequest(app, "GET", "http://example.com/users?page=2&limit=2&search=a%20b", nil)
+	handleError(t, err)
+	body, err := responseToString(resp)
+	handleError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"data":["c","d"],"meta":{"page":2,"limit":2,"total":5,"last_page":3,"from":3,"to":4},"message":"users"}`, body)
+	assert.Equal(t, `<http://example.com/users?limit=2&page=1&search=a+b>; rel="first", `+
+		`<http://example.com/users?limit=2&page=1&search=a+b>; rel="prev", `+
+		`<http://example.com/users?limit=2&page=3&search=a+b>; rel="next", `+
+		`<http://example.com/users?limit=2&page=3&search=a+b>; rel="last"`, resp.Header.Get(fiber.HeaderLink))
+}
+
+func TestSender_Paginated_ShouldOmitPrevAndNextOnSinglePage(t *testing.T) {
+	app := fiber.New()
+	app.Get("/users", func(c *fiber.Ctx) error {
+		return New(c).Paginated("users", nil, NewPaginationMeta(1, 10, 3))
+	})
+
+	resp, err := newTestRequest(app, "GET", "http://example.com/users", nil)
+	handleError(t, err)
+	body, err := responseToString(resp)
+	handleError(t, err)
+
+	assert.Contains(t, body, `"data":{}`)
+	assert.Equal(t, `<http://example.com/users?page=1>; rel="first", <http://example.com/users?page=1>; rel="last"`, resp.Header.Get(fiber.HeaderLink))
+}
+
+func TestSender_CursorPaginated_ExpectedBehavior(t *testing.T) {
+	app := fiber.New()
+	app.Get("/users", func(c *fiber.Ctx) error {
+		return New(c).CursorPaginated("users", []string{"a"}, CursorMeta{Limit: 1, NextCursor: "next.sig"})
+	})
+
+	resp, err := newTestRequest(app, "GET", "http://example.com/users?limit=1&cursor=current.sig", nil)
+	handleError(t, err)
+	body, err := responseToString(resp)
+	handleError(t, err)
+
+	assert.Equal(t, `{"data":["a"],"meta":{"limit":1,"next_cursor":"next.sig","prev_cursor":""},"message":"users"}`, body)
+	assert.Equal(t, `<http://example.com/users?cursor=next.sig&limit=1>; rel="next"`, resp.Header.Get(fiber.HeaderLink))
+}
@@ -0,0 +1,105 @@
+package resp
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MIMEProblemJSON is the media type of RFC 7807 problem documents.
+const MIMEProblemJSON = "application/problem+json"
+
+// modeKey is the fiber.Ctx locals key holding the Mode set by UseMode.
+const modeKey = "resp.mode"
+
+// Mode selects how Sender formats error responses.
+type Mode int
+
+const (
+	// ModeNegotiate sends problem documents only when the client accepts application/problem+json. Default.
+	ModeNegotiate Mode = iota
+	// ModeEnvelope always sends ErrorResponse and FormErrorResponse.
+	ModeEnvelope
+	// ModeProblem always sends RFC 7807 ProblemDetails.
+	ModeProblem
+)
+
+// UseMode is a middleware selecting the Mode used by every Sender created for the request.
+//
+//	app.Use(resp.UseMode(resp.ModeProblem))
+func UseMode(mode Mode) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(modeKey, mode)
+		return c.Next()
+	}
+}
+
+// Problem sends an RFC 7807 problem document. The type defaults to "about:blank", the title to the status text and the instance to the request URL.
+func (r Sender) Problem(p ProblemDetails) error {
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Instance == "" {
+		p.Instance = r.ctx.OriginalURL()
+	}
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	r.ctx.Status(p.Status)
+	r.ctx.Set(fiber.HeaderContentType, MIMEProblemJSON)
+	return r.ctx.Send(body)
+}
+
+// MarshalJSON merges the extension members into the problem document. Extensions cannot override the standard members.
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
+	type problem ProblemDetails
+	body, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return body, err
+	}
+
+	members := make(map[string]interface{}, len(p.Extensions))
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+	if err = json.Unmarshal(body, &members); err != nil {
+		return nil, err
+	}
+	return json.Marshal(members)
+}
+
+// useProblem checks if error responses should be sent as problem documents.
+func (r Sender) useProblem() bool {
+	mode, _ := r.ctx.Locals(modeKey).(Mode)
+	switch mode {
+	case ModeProblem:
+		return true
+	case ModeEnvelope:
+		return false
+	default:
+		return r.ctx.Accepts(fiber.MIMEApplicationJSON, MIMEProblemJSON) == MIMEProblemJSON
+	}
+}
+
+// invalidParams converts an ErrorBag into the invalid-params member, sorted by field name.
+func invalidParams(errors ErrorBag) []InvalidParam {
+	params := make([]InvalidParam, 0, len(errors))
+	for name, reason := range errors {
+		params = append(params, InvalidParam{Name: name, Reason: reason})
+	}
+	sort.Slice(params, func(i, j int) bool {
+		return params[i].Name < params[j].Name
+	})
+	return params
+}
@@ -0,0 +1,107 @@
+package resp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSender_Error_ShouldSendProblemInProblemMode(t *testing.T) {
+	app := fiber.New()
+	app.Use(UseMode(ModeProblem))
+	app.Get("/test", func(c *fiber.Ctx) error {
+		return New(c).NotFound("account not found", errors.New("no account with id 3"))
+	})
+
+	resp, err := newTestRequest(app, "GET", "/test?id=3", nil)
+	handleError(t, err)
+	body, err := responseToString(resp)
+	handleError(t, err)
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, MIMEProblemJSON, resp.Header.Get(fiber.HeaderContentType))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "account not found",
+		"status": 404,
+		"detail": "no account with id 3",
+		"instance": "/test?id=3"
+	}`, body)
+}
+
+func TestSender_FormError_ShouldSendInvalidParamsInProblemMode(t *testing.T) {
+	app := fiber.New()
+	app.Use(UseMode(ModeProblem))
+	app.Get("/test", func(c *fiber.Ctx) error {
+		return New(c).FormError("creating account", ErrorBag{"username": "Username is required", "password": "Password is required"})
+	})
+
+	resp, err := newTestRequest(app, "GET", "/test", nil)
+	handleError(t, err)
+	body, err := responseToString(resp)
+	handleError(t, err)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	assert.Contains(t, body, `"invalid-params":[{"name":"password","reason":"Password is required"},{"name":"username","reason":"Username is required"}]`)
+}
+
+func TestSender_Error_ShouldNegotiateProblem(t *testing.T) {
+	app := fiber.New()
+	app.Get("/test", func(c *fiber.Ctx) error {
+		return New(c).BadRequest("testing", errors.New("test error"))
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(fiber.HeaderAccept, MIMEProblemJSON)
+	resp, err := app.Test(req, 15000)
+	handleError(t, err)
+	assert.Equal(t, MIMEProblemJSON, resp.Header.Get(fiber.HeaderContentType))
+
+	resp, err = newTestRequest(app, "GET", "/test", nil)
+	handleError(t, err)
+	body, err := responseToString(resp)
+	handleError(t, err)
+	assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get(fiber.HeaderContentType))
+	assert.Contains(t, body, `"error":"test error"`)
+}
+
+func TestSender_Error_EnvelopeModeShouldIgnoreNegotiation(t *testing.T) {
+	app := fiber.New()
+	app.Use(UseMode(ModeEnvelope))
+	app.Get("/test", func(c *fiber.Ctx) error {
+		return New(c).BadRequest("testing", errors.New("test error"))
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(fiber.HeaderAccept, MIMEProblemJSON)
+	resp, err := app.Test(req, 15000)
+	handleError(t, err)
+
+	assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get(fiber.HeaderContentType))
+}
+
+func TestSender_Problem_ShouldMergeExtensions(t *testing.T) {
+	app := fiber.New()
+	app.Get("/test", func(c *fiber.Ctx) error {
+		return New(c).Problem(ProblemDetails{
+			Type:       "https://example.com/probs/out-of-credit",
+			Title:      "You do not have enough credit.",
+			Status:     http.StatusForbidden,
+			Extensions: map[string]interface{}{"balance": 30, "status": 200},
+		})
+	})
+
+	resp, err := newTestRequest(app, "GET", "/test", nil)
+	handleError(t, err)
+	body, err := responseToString(resp)
+	handleError(t, err)
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Contains(t, body, `"balance":30`)
+	// extensions cannot override the standard members
+	assert.Contains(t, body, `"status":403`)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"net/http"
+	"strings"
 )
 
 // Sender is a struct that holds the fiber.Ctx needed to send curated API responses.
@@ -16,19 +17,9 @@ func New(c *fiber.Ctx) Sender {
 
 // Success sends a message and data interface with a http.StatusOK status code
 func (r Sender) Success(msg string, data interface{}) error {
-	_ = r.ctx.SendStatus(http.StatusOK)
-
-	// prevent `null` values in data when arrays are empty
-	if fmt.Sprintf("%t", data) == "[]" {
-		data = make([]string, 0)
-	}
-	if data == nil {
-		data = fiber.Map{}
-	}
-
-	return r.ctx.JSON(SuccessResponse{
+	return r.send(http.StatusOK, SuccessResponse{
 		Message: msg,
-		Data:    data,
+		Data:    normalizeData(data),
 	})
 }
 
@@ -57,10 +48,18 @@ func (r Sender) NotFound(msg string, err error) error {
 	return r.sendErrorWithStatusCode(msg, err, http.StatusNotFound)
 }
 
+// ErrorWithStatus is a helper function to send an error with any given status code
+func (r Sender) ErrorWithStatus(msg string, err error, code int) error {
+	return r.sendErrorWithStatusCode(msg, err, code)
+}
+
 // sendErrorWithStatusCode sends a message and error with a given status code
 func (r Sender) sendErrorWithStatusCode(msg string, err error, code int) error {
-	_ = r.ctx.SendStatus(code)
-	return r.ctx.JSON(ErrorResponse{
+	if r.useProblem() {
+		return r.Problem(ProblemDetails{Status: code, Title: msg, Detail: err.Error()})
+	}
+
+	return r.send(code, ErrorResponse{
 		Message: msg,
 		Error:   err.Error(),
 	})
@@ -68,10 +67,48 @@ func (r Sender) sendErrorWithStatusCode(msg string, err error, code int) error {
 
 // sendFormErrorWithStatusCode sends a message and ErrorBag with a given status code
 func (r Sender) sendFormErrorWithStatusCode(msg string, errors ErrorBag, code int) error {
-	_ = r.ctx.SendStatus(code)
+	if r.useProblem() {
+		return r.Problem(ProblemDetails{Status: code, Title: msg, InvalidParams: invalidParams(errors)})
+	}
 
-	return r.ctx.JSON(FormErrorResponse{
+	return r.send(code, FormErrorResponse{
 		Message: msg,
 		Errors:  errors,
 	})
 }
+
+// send encodes the body with the encoder matching the Accept header. Sends a http.StatusNotAcceptable JSON error when no registered media type is accepted.
+func (r Sender) send(code int, body interface{}) error {
+	mediaType, encode, ok := encoders.negotiate(r.ctx)
+	if !ok {
+		_ = r.ctx.SendStatus(http.StatusNotAcceptable)
+		return r.ctx.JSON(ErrorResponse{
+			Message: http.StatusText(http.StatusNotAcceptable),
+			Error:   "supported media types: " + strings.Join(encoders.supported(), ", "),
+		})
+	}
+
+	if encode == nil {
+		encode = Encoder(r.ctx.App().Config().JSONEncoder)
+	}
+
+	b, err := encode(body)
+	if err != nil {
+		return err
+	}
+
+	r.ctx.Status(code)
+	r.ctx.Set(fiber.HeaderContentType, mediaType)
+	return r.ctx.Send(b)
+}
+
+// normalizeData prevents `null` values in data when arrays are empty or data is nil
+func normalizeData(data interface{}) interface{} {
+	if fmt.Sprintf("%t", data) == "[]" {
+		return make([]string, 0)
+	}
+	if data == nil {
+		return fiber.Map{}
+	}
+	return data
+}
@@ -217,6 +217,26 @@ func TestSender_NotFound(t *testing.T) {
 	assert.Contains(t, body, `"error":"test error"`)
 }
 
+func TestSender_ErrorWithStatus(t *testing.T) {
+	app := fiber.New()
+	app.Get("/test", func(c *fiber.Ctx) error {
+		err := New(c).ErrorWithStatus("testing", errors.New("test error"), http.StatusConflict)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return nil
+	})
+
+	resp, err := newTestRequest(app, "GET", "/test", nil)
+	handleError(t, err)
+	body, err := responseToString(resp)
+	handleError(t, err)
+
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.Contains(t, body, "testing")
+	assert.Contains(t, body, `"error":"test error"`)
+}
+
 func handleError(t *testing.T, err error) {
 	if err != nil {
 		t.Fatal(err.Error())
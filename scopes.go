@@ -0,0 +1,307 @@
+package napi
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FilterOperator compares a column with the value given in CanFilter.FilterBy.
+type FilterOperator string
+
+const (
+	FilterEq   FilterOperator = "eq"
+	FilterNeq  FilterOperator = "neq"
+	FilterGt   FilterOperator = "gt"
+	FilterGte  FilterOperator = "gte"
+	FilterLt   FilterOperator = "lt"
+	FilterLte  FilterOperator = "lte"
+	FilterLike FilterOperator = "like"
+	// FilterIn matches any of the values separated by a pipe, e.g. `filter_by=role:admin|editor`.
+	FilterIn FilterOperator = "in"
+)
+
+// ScopeOptions whitelists the columns a Paginater may touch when applied to a gorm query. Values outside the whitelist are ignored, so request input never reaches the SQL as an identifier.
+type ScopeOptions struct {
+	// OrderColumns columns allowed in order_by.
+	OrderColumns []string
+	// Sort sort keys allowed in sort, or in order_by. Replaces OrderColumns when set.
+	Sort SortRules
+	// SearchColumns columns matched with LIKE against search. Any matching column is enough.
+	SearchColumns []string
+	// Filters columns allowed in filter_by and the operator used to compare them.
+	Filters map[string]FilterOperator
+}
+
+// Scope returns a gorm scope applying the filter, search, order and pagination of the request.
+//
+//	db.Model(&Account{}).Scopes(req.Scope(opts)).Find(&accounts)
+func (p *Paginater) Scope(opts ScopeOptions) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Scopes(
+			p.FilterScope(opts.Filters),
+			p.SearchScope(opts.SearchColumns...),
+			opts.orderScope(&p.CanOrder),
+			p.PaginateScope(),
+		)
+	}
+}
+
+// Paginate runs the page query and the COUNT(*) of all matching rows. The count ignores order and pagination.
+func Paginate[T any](db *gorm.DB, p *Paginater, opts ScopeOptions) ([]T, int64, error) {
+	var total int64
+	query := db.Model(new(T)).Scopes(p.FilterScope(opts.Filters), p.SearchScope(opts.SearchColumns...))
+	if tx := query.Session(&gorm.Session{}).Count(&total); tx.Error != nil {
+		return nil, 0, tx.Error
+	}
+
+	items := make([]T, 0)
+	if tx := query.Scopes(opts.orderScope(&p.CanOrder), p.PaginateScope()).Find(&items); tx.Error != nil {
+		return nil, 0, tx.Error
+	}
+
+	return items, total, nil
+}
+
+// orderScope uses the sort rules when set, the order columns otherwise.
+func (opts ScopeOptions) orderScope(req *CanOrder) func(db *gorm.DB) *gorm.DB {
+	if opts.Sort != nil {
+		return req.SortScope(opts.Sort)
+	}
+	return req.OrderScope(opts.OrderColumns...)
+}
+
+// PaginateScope returns a gorm scope applying the limit and offset of the current page. Does nothing when no limit is set.
+func (req *CanPaginate) PaginateScope() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if req.Limit < 1 {
+			return db
+		}
+		offset := req.Offset()
+		if offset < 0 {
+			offset = 0
+		}
+		return db.Offset(offset).Limit(req.Limit)
+	}
+}
+
+// OrderScope returns a gorm scope ordering by order_by when it is one of the given columns.
+func (req *CanOrder) OrderScope(columns ...string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !containsString(columns, req.OrderBy) {
+			return db
+		}
+		return db.Order(clause.OrderByColumn{
+			Column: clause.Column{Name: req.OrderBy},
+			Desc:   strings.EqualFold(req.OrderDir, "desc"),
+		})
+	}
+}
+
+// SearchScope returns a gorm scope matching search with LIKE '%search%' against any of the given columns. The % and _ wildcards of search are escaped, matching it literally.
+func (req *CanSearch) SearchScope(columns ...string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if req.Search == "" || len(columns) == 0 {
+			return db
+		}
+
+		exprs := make([]clause.Expression, len(columns))
+		for i, col := range columns {
+			exprs[i] = likeContains{Column: clause.Column{Name: col}, Value: req.Search}
+		}
+		return db.Where(clause.Or(exprs...))
+	}
+}
+
+// FilterScope returns a gorm scope applying filter_by, a comma separated list of `column:value` pairs, and the Filters set by ParseFilters. Columns of filter_by missing from filters are ignored.
+func (req *CanFilter) FilterScope(filters map[string]FilterOperator) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		values := req.FilterValues()
+		columns := make([]string, 0, len(filters))
+		for col := range filters {
+			columns = append(columns, col)
+		}
+		sort.Strings(columns)
+
+		bag := errorBag{}
+		for _, col := range columns {
+			vals, ok := values[col]
+			if !ok {
+				continue
+			}
+			if filters[col] == FilterIn {
+				vals = splitFilterValues(vals, "|")
+			}
+			expr, msg := filterExpression(filterColumnType(db, col), col, filters[col], vals)
+			if msg != "" {
+				bag[col] = msg
+				continue
+			}
+			if expr != nil {
+				db = db.Where(expr)
+			}
+		}
+		if len(bag) > 0 {
+			_ = db.AddError((&ValidationError{bag: bag}).Err())
+		}
+		return db.Scopes(req.Filters.Scope())
+	}
+}
+
+// FilterValues parses filter_by into the values of each column, e.g. `status:active,role:admin|editor`.
+func (req *CanFilter) FilterValues() map[string][]string {
+	values := map[string][]string{}
+	for _, pair := range strings.Split(req.FilterBy, ",") {
+		col, val, ok := strings.Cut(pair, ":")
+		col = strings.TrimSpace(col)
+		if !ok || col == "" {
+			continue
+		}
+		values[col] = append(values[col], strings.TrimSpace(val))
+	}
+	return values
+}
+
+// filterExpression builds the where clause of a single filtered column. FilterIn matches any of the values, the other operators use the last one.
+// Values are converted to typ, the type of the column, except for FilterLike. Returns an error message for values that do not convert.
+func filterExpression(typ reflect.Type, col string, op FilterOperator, vals []string) (clause.Expression, string) {
+	column := clause.Column{Name: col}
+	if op == FilterLike {
+		return likeContains{Column: column, Value: vals[len(vals)-1]}, ""
+	}
+
+	typed := make([]interface{}, len(vals))
+	for i, val := range vals {
+		var msg string
+		if typed[i], msg = typedFilterValue(typ, val); msg != "" {
+			return nil, msg
+		}
+	}
+
+	if op == FilterIn {
+		return clause.IN{Column: column, Values: typed}, ""
+	}
+
+	val := typed[len(typed)-1]
+	switch op {
+	case FilterEq:
+		return clause.Eq{Column: column, Value: val}, ""
+	case FilterNeq:
+		return clause.Neq{Column: column, Value: val}, ""
+	case FilterGt:
+		return clause.Gt{Column: column, Value: val}, ""
+	case FilterGte:
+		return clause.Gte{Column: column, Value: val}, ""
+	case FilterLt:
+		return clause.Lt{Column: column, Value: val}, ""
+	case FilterLte:
+		return clause.Lte{Column: column, Value: val}, ""
+	}
+	return nil, ""
+}
+
+// filterColumnType is the type of the field of the column in the model of the query, or nil when the query has no model or the model no such column.
+func filterColumnType(db *gorm.DB, col string) reflect.Type {
+	stmt := db.Statement
+	if stmt.Schema == nil {
+		// scopes run before gorm parses the model, so it is parsed here the same way
+		model := stmt.Model
+		if model == nil {
+			model = stmt.Dest
+		}
+		if model == nil || stmt.Parse(model) != nil {
+			return nil
+		}
+	}
+
+	field := stmt.Schema.LookUpField(col)
+	if field == nil {
+		return nil
+	}
+	return field.FieldType
+}
+
+// typedFilterValue converts a filter value to the type of its column, so numbers, booleans and dates are bound as such rather than as strings.
+// Values of other types, or of an unknown column, are kept as strings. Returns an error message for values that do not convert.
+func typedFilterValue(typ reflect.Type, val string) (interface{}, string) {
+	if typ == nil {
+		return val, ""
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ == reflect.TypeOf(time.Time{}) {
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if t, err := time.Parse(layout, val); err == nil {
+				return t, ""
+			}
+		}
+		return nil, "must be a date, e.g. 2024-01-31 or 2024-01-31T10:00:00Z"
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.ParseInt(val, 10, typ.Bits()); err == nil {
+			return i, ""
+		}
+		return nil, "must be an integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u, err := strconv.ParseUint(val, 10, typ.Bits()); err == nil {
+			return u, ""
+		}
+		return nil, "must be a positive integer"
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(val, typ.Bits()); err == nil {
+			return f, ""
+		}
+		return nil, "must be a number"
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(val); err == nil {
+			return b, ""
+		}
+		return nil, "must be true or false"
+	}
+	return val, ""
+}
+
+// likeEscaper escapes the LIKE wildcards with the ! escape character, which unlike \ needs no quoting in any dialect.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// likeContains is a LIKE '%value%' expression matching the value literally.
+type likeContains struct {
+	Column clause.Column
+	Value  string
+}
+
+// Build writes the expression with an ESCAPE clause.
+func (like likeContains) Build(builder clause.Builder) {
+	builder.WriteQuoted(like.Column)
+	builder.WriteString(" LIKE ")
+	builder.AddVar(builder, "%"+likeEscaper.Replace(like.Value)+"%")
+	builder.WriteString(" ESCAPE '!'")
+}
+
+// splitFilterValues splits every value by the separator.
+func splitFilterValues(vals []string, sep string) []string {
+	var split []string
+	for _, val := range vals {
+		split = append(split, strings.Split(val, sep)...)
+	}
+	return split
+}
+
+// containsString checks if the slice contains the string.
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package napi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	glog "gorm.io/gorm/logger"
+)
+
+type testScopeModel struct {
+	ID       uint
+	Username string
+	Status   string
+	Age      int
+}
+
+func testScopeOptions() ScopeOptions {
+	return ScopeOptions{
+		OrderColumns:  []string{"username", "age"},
+		SearchColumns: []string{"username", "status"},
+		Filters: map[string]FilterOperator{
+			"status": FilterIn,
+			"age":    FilterGte,
+		},
+	}
+}
+
+// testGormDB opens a private in-memory sqlite database migrated with the given models.
+func testGormDB(t *testing.T, models ...interface{}) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{Logger: glog.Default.LogMode(glog.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func testSeedScopeModels(t *testing.T) *gorm.DB {
+	db := testGormDB(t, &testScopeModel{})
+	models := []testScopeModel{
+		{Username: "alice", Status: "active", Age: 30},
+		{Username: "bob", Status: "banned", Age: 20},
+		{Username: "carol", Status: "active", Age: 40},
+		{Username: "dave", Status: "pending", Age: 50},
+		{Username: "erin", Status: "active", Age: 25},
+	}
+	if err := db.Create(&models).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestPaginate_ExpectedBehavior(t *testing.T) {
+	db := testSeedScopeModels(t)
+	p := &Paginater{
+		CanPaginate: CanPaginate{Page: 2, Limit: 2},
+		CanOrder:    CanOrder{OrderBy: "age", OrderDir: "desc"},
+	}
+
+	items, total, err := Paginate[testScopeModel](db, p, testScopeOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+	assert.Equal(t, []string{"alice", "erin"}, testScopeUsernames(items))
+}
+
+func TestPaginate_ShouldFilterAndSearch(t *testing.T) {
+	db := testSeedScopeModels(t)
+	p := &Paginater{
+		CanPaginate: CanPaginate{Page: 1, Limit: 10},
+		CanOrder:    CanOrder{OrderBy: "username", OrderDir: "asc"},
+		CanFilter:   CanFilter{FilterBy: "status:active|pending,age:26"},
+		CanSearch:   CanSearch{Search: "a"},
+	}
+
+	items, total, err := Paginate[testScopeModel](db, p, testScopeOptions())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, []string{"alice", "carol", "dave"}, testScopeUsernames(items))
+}
+
+func TestPaginater_Scope_ShouldIgnoreColumnsOutsideTheWhitelist(t *testing.T) {
+	db := testSeedScopeModels(t)
+	p := &Paginater{
+		CanOrder:  CanOrder{OrderBy: "id; DROP TABLE test_scope_models", OrderDir: "desc"},
+		CanFilter: CanFilter{FilterBy: "username:bob"},
+	}
+
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&testScopeModel{}).Scopes(p.Scope(testScopeOptions())).Find(&[]testScopeModel{})
+	})
+	assert.Equal(t, "SELECT * FROM `test_scope_models`", sql)
+}
+
+func TestPaginate_ShouldMatchWildcardsLiterally(t *testing.T) {
+	db := testSeedScopeModels(t)
+	db.Create(&testScopeModel{Username: "50%_off", Status: "active"})
+
+	for _, search := range []string{"%", "_", "!"} {
+		items, _, err := Paginate[testScopeModel](db, &Paginater{CanSearch: CanSearch{Search: search}}, testScopeOptions())
+		assert.NoError(t, err)
+		if search == "!" {
+			assert.Empty(t, items)
+		} else {
+			assert.Equal(t, []string{"50%_off"}, testScopeUsernames(items))
+		}
+	}
+
+	var items []testScopeModel
+	filters := Filters{{Field: "username", Operator: FilterLike, Values: []string{"l_c"}}}
+	assert.NoError(t, db.Scopes(filters.Scope()).Find(&items).Error)
+	assert.Empty(t, items)
+}
+
+func TestCanFilter_FilterValues(t *testing.T) {
+	f := CanFilter{FilterBy: "status:active, role:admin|editor,invalid,:empty"}
+
+	assert.Equal(t, map[string][]string{
+		"status": {"active"},
+		"role":   {"admin|editor"},
+	}, f.FilterValues())
+}
+
+func testScopeUsernames(items []testScopeModel) []string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Username
+	}
+	return names
+}
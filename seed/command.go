@@ -0,0 +1,74 @@
+package seed
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/netr/napi/factory"
+	"gorm.io/gorm"
+)
+
+// Command is the command line entry point running the seeders, typically called with os.Args[2:] of "app seed ...".
+//
+//	-env string       environment the seeders run in (default "dev")
+//	-rand-seed int    seeds the fake data generator, making demo and staging databases reproducible
+//	-only string      comma separated names of the seeders to run, with their dependencies
+//	-list             lists the seeders in the order they run instead of running them
+func Command(db *gorm.DB, f *factory.Factory, seeders []Seeder, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.SetOutput(out)
+	env := flags.String("env", DefaultEnv, "environment the seeders run in")
+	randSeed := flags.Int64("rand-seed", 0, "seeds the fake data generator, 0 keeps it random")
+	only := flags.String("only", "", "comma separated names of the seeders to run")
+	list := flags.Bool("list", false, "list the seeders instead of running them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	opts := []Option{WithEnv(*env)}
+	if *randSeed != 0 {
+		opts = append(opts, WithRandSeed(*randSeed))
+	}
+	r := New(db, f, opts...)
+
+	if *only == "" {
+		r.Register(seeders...)
+	} else {
+		for _, name := range strings.Split(*only, ",") {
+			seeder, ok := findSeeder(seeders, strings.TrimSpace(name))
+			if !ok {
+				return fmt.Errorf("seed: unknown seeder %s", name)
+			}
+			r.Register(seeder)
+		}
+	}
+
+	names, err := r.Seeders()
+	if err != nil {
+		return err
+	}
+	if *list {
+		for _, name := range names {
+			_, _ = fmt.Fprintln(out, name)
+		}
+		return nil
+	}
+
+	if err = r.Run(); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "seeded %s: %s\n", r.Env(), strings.Join(names, ", "))
+	return nil
+}
+
+// findSeeder gets a seeder by name.
+func findSeeder(seeders []Seeder, name string) (Seeder, bool) {
+	for _, seeder := range seeders {
+		if Name(seeder) == name {
+			return seeder, true
+		}
+	}
+	return nil, false
+}
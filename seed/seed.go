@@ -0,0 +1,258 @@
+// Package seed
+// Database seeders run in dependency order per environment, creating rows with factory.Factory.
+
+package seed
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/netr/napi/factory"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultTable is the table recording the idempotent seeders that already ran.
+	DefaultTable = "napi_seeders"
+	// DefaultEnv is the environment of a Registry created without WithEnv.
+	DefaultEnv = "dev"
+)
+
+// ErrCycle is returned when seeders depend on each other.
+var ErrCycle = errors.New("seeder dependency cycle")
+
+// Context is passed to running seeders. DB and Factory are bound to the seeder's transaction.
+type Context struct {
+	DB      *gorm.DB
+	Factory *factory.Factory
+	Env     string
+}
+
+// Seeder creates rows in the database.
+type Seeder interface {
+	Run(ctx *Context) error
+}
+
+// Named seeders are identified by their name instead of their type, e.g. in the napi_seeders table.
+type Named interface {
+	Name() string
+}
+
+// Dependent seeders run after their dependencies, which don't need to be registered.
+type Dependent interface {
+	Dependencies() []Seeder
+}
+
+// Environmental seeders only run in the listed environments. Other seeders run in every environment.
+type Environmental interface {
+	Environments() []string
+}
+
+// Idempotent seeders returning true run once per database. They are recorded in the napi_seeders table and skipped afterwards.
+type Idempotent interface {
+	Idempotent() bool
+}
+
+// Record is a row of the napi_seeders table.
+type Record struct {
+	Name  string `gorm:"primaryKey;size:255"`
+	RanAt time.Time
+}
+
+// Registry runs seeders.
+type Registry struct {
+	db       *gorm.DB
+	factory  *factory.Factory
+	env      string
+	table    string
+	randSeed *int64
+	seeders  []Seeder
+}
+
+// Option type used for option pattern
+type Option func(*Registry)
+
+// WithEnv sets the environment the seeders run in, e.g. dev, test, staging or demo. Defaults to dev.
+func WithEnv(env string) Option {
+	return func(r *Registry) {
+		r.env = env
+	}
+}
+
+// WithRandSeed seeds the fake data generator before running, so the same seeders always create the same rows.
+func WithRandSeed(seed int64) Option {
+	return func(r *Registry) {
+		r.randSeed = &seed
+	}
+}
+
+// WithTable sets the table recording the idempotent seeders. Defaults to napi_seeders.
+func WithTable(table string) Option {
+	return func(r *Registry) {
+		r.table = table
+	}
+}
+
+// New creates a Registry creating rows in db with the factories of f. A nil f uses an empty factory.
+//
+//	err := seed.New(db, f, seed.WithEnv("staging"), seed.WithRandSeed(42)).Register(AccountSeeder{}).Run()
+func New(db *gorm.DB, f *factory.Factory, opts ...Option) *Registry {
+	if f == nil {
+		f = factory.New(db)
+	}
+
+	r := &Registry{db: db, factory: f, env: DefaultEnv, table: DefaultTable}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Register adds seeders run by Run.
+func (r *Registry) Register(seeders ...Seeder) *Registry {
+	r.seeders = append(r.seeders, seeders...)
+	return r
+}
+
+// Env returns the environment the seeders run in.
+func (r *Registry) Env() string {
+	return r.env
+}
+
+// Seeders returns the names of the registered seeders and their dependencies, in the order they run.
+func (r *Registry) Seeders() ([]string, error) {
+	ordered, err := order(r.seeders)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(ordered))
+	for i, seeder := range ordered {
+		names[i] = Name(seeder)
+	}
+	return names, nil
+}
+
+// Run runs the seeders, or every registered seeder when none are given, after their dependencies.
+// Each seeder runs in its own transaction. Seeders not meant for the environment and idempotent seeders that already ran are skipped.
+func (r *Registry) Run(seeders ...Seeder) error {
+	if len(seeders) == 0 {
+		seeders = r.seeders
+	}
+	ordered, err := order(seeders)
+	if err != nil {
+		return err
+	}
+
+	if r.randSeed != nil {
+		factory.SetSeed(*r.randSeed)
+	}
+	if err = r.db.Table(r.table).AutoMigrate(&Record{}); err != nil {
+		return err
+	}
+
+	for _, seeder := range ordered {
+		if !runsIn(seeder, r.env) {
+			continue
+		}
+		if err = r.run(seeder); err != nil {
+			return fmt.Errorf("seed: %s: %w", Name(seeder), err)
+		}
+	}
+	return nil
+}
+
+// run runs a seeder in a transaction, recording it when idempotent.
+func (r *Registry) run(seeder Seeder) error {
+	name := Name(seeder)
+	once := false
+	if i, ok := seeder.(Idempotent); ok {
+		once = i.Idempotent()
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if once {
+			var count int64
+			if err := tx.Table(r.table).Where("name = ?", name).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return nil
+			}
+		}
+
+		if err := seeder.Run(&Context{DB: tx, Factory: r.factory.WithDB(tx), Env: r.env}); err != nil {
+			return err
+		}
+
+		if once {
+			return tx.Table(r.table).Create(&Record{Name: name, RanAt: time.Now()}).Error
+		}
+		return nil
+	})
+}
+
+// Name returns the name of a Named seeder, or its type otherwise.
+func Name(seeder Seeder) string {
+	if n, ok := seeder.(Named); ok {
+		return n.Name()
+	}
+	return strings.TrimPrefix(reflect.TypeOf(seeder).String(), "*")
+}
+
+// runsIn checks if the seeder is meant for the environment.
+func runsIn(seeder Seeder, env string) bool {
+	e, ok := seeder.(Environmental)
+	if !ok {
+		return true
+	}
+	for _, allowed := range e.Environments() {
+		if allowed == env {
+			return true
+		}
+	}
+	return false
+}
+
+// order sorts the seeders and their dependencies so every seeder comes after its dependencies. Seeders with the same name run once.
+func order(seeders []Seeder) ([]Seeder, error) {
+	var ordered []Seeder
+	done := map[string]bool{}
+	visiting := map[string]bool{}
+
+	var visit func(seeder Seeder, path []string) error
+	visit = func(seeder Seeder, path []string) error {
+		name := Name(seeder)
+		if done[name] {
+			return nil
+		}
+		path = append(path, name)
+		if visiting[name] {
+			return fmt.Errorf("%w: %s", ErrCycle, strings.Join(path, " -> "))
+		}
+
+		visiting[name] = true
+		if d, ok := seeder.(Dependent); ok {
+			for _, dep := range d.Dependencies() {
+				if err := visit(dep, path); err != nil {
+					return err
+				}
+			}
+		}
+		visiting[name] = false
+
+		done[name] = true
+		ordered = append(ordered, seeder)
+		return nil
+	}
+
+	for _, seeder := range seeders {
+		if err := visit(seeder, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
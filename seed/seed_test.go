@@ -0,0 +1,179 @@
+package seed
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/bxcodec/faker/v3"
+	"github.com/netr/napi/factory"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	glog "gorm.io/gorm/logger"
+)
+
+type testUser struct {
+	ID       uint
+	Username string
+	Role     string
+}
+
+func (u *testUser) Make() interface{} {
+	return &testUser{Username: faker.Username(), Role: "user"}
+}
+
+type testRoleSeeder struct{}
+
+func (testRoleSeeder) Run(ctx *Context) error {
+	ctx.Factory.Create(testUser{Role: "admin"})
+	return nil
+}
+
+func (testRoleSeeder) Idempotent() bool { return true }
+
+type testUserSeeder struct{ count int }
+
+func (s testUserSeeder) Run(ctx *Context) error {
+	var admins int64
+	ctx.DB.Model(&testUser{}).Where("role = ?", "admin").Count(&admins)
+	if admins == 0 {
+		return errors.New("admin should have been seeded first")
+	}
+	for i := 0; i < s.count; i++ {
+		ctx.Factory.Create(testUser{})
+	}
+	return nil
+}
+
+func (testUserSeeder) Dependencies() []Seeder { return []Seeder{testRoleSeeder{}} }
+
+type testDemoSeeder struct{}
+
+func (testDemoSeeder) Run(ctx *Context) error {
+	ctx.Factory.Create(testUser{Username: "demo"})
+	return nil
+}
+
+func (testDemoSeeder) Environments() []string { return []string{"demo", "staging"} }
+
+type testCycleSeeder struct{ name string }
+
+func (s testCycleSeeder) Run(*Context) error { return nil }
+func (s testCycleSeeder) Name() string       { return s.name }
+func (s testCycleSeeder) Dependencies() []Seeder {
+	return []Seeder{testCycleSeeder{name: map[string]string{"a": "b", "b": "a"}[s.name]}}
+}
+
+type testFailingSeeder struct{}
+
+func (testFailingSeeder) Run(ctx *Context) error {
+	ctx.Factory.Create(testUser{Username: "rolled back"})
+	return errors.New("failed")
+}
+
+func testDB(t *testing.T) (*gorm.DB, *factory.Factory) {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: glog.Default.LogMode(glog.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+	if err = db.AutoMigrate(&testUser{}); err != nil {
+		t.Fatal(err)
+	}
+	return db, factory.New(db).Add(&testUser{})
+}
+
+func testUsernames(t *testing.T, db *gorm.DB) []string {
+	var names []string
+	if err := db.Model(&testUser{}).Order("id").Pluck("username", &names).Error; err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+func TestRegistry_Run_ShouldRunDependenciesFirst(t *testing.T) {
+	db, f := testDB(t)
+
+	err := New(db, f).Register(testUserSeeder{count: 2}).Run()
+	assert.NoError(t, err)
+
+	var users []testUser
+	db.Order("id").Find(&users)
+	assert.Len(t, users, 3)
+	assert.Equal(t, "admin", users[0].Role)
+}
+
+func TestRegistry_Run_ShouldSkipIdempotentSeedersThatRan(t *testing.T) {
+	db, f := testDB(t)
+	r := New(db, f)
+
+	assert.NoError(t, r.Run(testUserSeeder{count: 1}))
+	assert.NoError(t, r.Run(testUserSeeder{count: 1}))
+
+	var admins int64
+	db.Model(&testUser{}).Where("role = ?", "admin").Count(&admins)
+	assert.Equal(t, int64(1), admins)
+	assert.Len(t, testUsernames(t, db), 3)
+}
+
+func TestRegistry_Run_ShouldFilterByEnvironment(t *testing.T) {
+	db, f := testDB(t)
+
+	assert.NoError(t, New(db, f).Run(testDemoSeeder{}))
+	assert.Empty(t, testUsernames(t, db))
+
+	assert.NoError(t, New(db, f, WithEnv("demo")).Run(testDemoSeeder{}))
+	assert.Equal(t, []string{"demo"}, testUsernames(t, db))
+}
+
+func TestRegistry_Run_ShouldBeReproducible(t *testing.T) {
+	db, f := testDB(t)
+
+	assert.NoError(t, New(db, f, WithRandSeed(42)).Run(testUserSeeder{count: 3}))
+	first := testUsernames(t, db)
+
+	db.Where("1 = 1").Delete(&testUser{})
+	db.Table(DefaultTable).Where("1 = 1").Delete(&Record{})
+
+	assert.NoError(t, New(db, f, WithRandSeed(42)).Run(testUserSeeder{count: 3}))
+	assert.Equal(t, first, testUsernames(t, db))
+}
+
+func TestRegistry_Run_ShouldRollbackFailedSeeder(t *testing.T) {
+	db, f := testDB(t)
+
+	err := New(db, f).Run(testFailingSeeder{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "seed.testFailingSeeder")
+	assert.Empty(t, testUsernames(t, db))
+}
+
+func TestRegistry_Seeders_ShouldDetectCycles(t *testing.T) {
+	_, err := New(nil, factory.New(nil)).Register(testCycleSeeder{name: "a"}).Seeders()
+	assert.ErrorIs(t, err, ErrCycle)
+	assert.Contains(t, err.Error(), "a -> b -> a")
+}
+
+func TestCommand(t *testing.T) {
+	db, f := testDB(t)
+	seeders := []Seeder{testUserSeeder{count: 1}, testDemoSeeder{}}
+
+	var out bytes.Buffer
+	assert.NoError(t, Command(db, f, seeders, []string{"-list"}, &out))
+	assert.Equal(t, "seed.testRoleSeeder\nseed.testUserSeeder\nseed.testDemoSeeder\n", out.String())
+	assert.Empty(t, testUsernames(t, db))
+
+	out.Reset()
+	assert.NoError(t, Command(db, f, seeders, []string{"-env", "staging", "-only", "seed.testDemoSeeder"}, &out))
+	assert.Equal(t, []string{"demo"}, testUsernames(t, db))
+	assert.Equal(t, "seeded staging: seed.testDemoSeeder\n", out.String())
+
+	assert.Error(t, Command(db, f, seeders, []string{"-only", "missing"}, &out))
+}
@@ -1,11 +1,16 @@
 package napi
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"github.com/gofiber/fiber/v2/middleware/cache"
 	"github.com/gofiber/fiber/v2/utils"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -22,14 +27,37 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/pprof"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/netr/napi/health"
 	"github.com/netr/napi/middleware"
+	"github.com/netr/napi/resp"
 )
 
+// ErrShutdownTimeout is returned by RunContext when open connections are not drained within the shutdown timeout.
+var ErrShutdownTimeout = errors.New("server shutdown timed out")
+
+// defaultShutdownTimeout is how long RunContext waits for connections to drain and for shutdown hooks to finish.
+const defaultShutdownTimeout = time.Second * 30
+
 // Server fiber app instance
 type Server struct {
-	app      *fiber.App
-	catchAll bool
-	port     int
+	app             *fiber.App
+	catchAll        bool
+	port            int
+	shutdownTimeout time.Duration
+	startHooks      []Hook
+	shutdownHooks   []Hook
+	tlsConfig       *tls.Config
+	tlsCertFile     string
+	tlsKeyFile      string
+	clientCAs       *x509.CertPool
+	unixSocket      string
+	unixSocketMode  os.FileMode
+	listener        net.Listener
+	admin           *fiber.App
+	adminPort       int
+	operational     []func(app *fiber.App, public bool)
+	healthChecks    *health.Registry
+	prometheus      *middleware.Prometheus
 }
 
 // ServerOption type used for option pattern
@@ -39,8 +67,10 @@ type ServerOption func(*Server)
 func NewServer(fiberCfg fiber.Config, opts ...ServerOption) *Server {
 	app := fiber.New(fiberCfg)
 	s := &Server{
-		app:  app,
-		port: 1337,
+		app:             app,
+		port:            1337,
+		shutdownTimeout: defaultShutdownTimeout,
+		healthChecks:    health.New(),
 	}
 
 	for _, opt := range opts {
@@ -63,6 +93,48 @@ func WithPort(p int) ServerOption {
 	}
 }
 
+// WithShutdownTimeout sets how long the server waits for open connections to drain, and for shutdown hooks to finish, before giving up. Default is 30 seconds.
+func WithShutdownTimeout(d time.Duration) ServerOption {
+	return func(s *Server) {
+		s.ShutdownTimeout(d)
+	}
+}
+
+// WithOnStart registers hooks that are run in order before the server starts listening. The first failing hook aborts the start.
+func WithOnStart(hooks ...Hook) ServerOption {
+	return func(s *Server) {
+		s.OnStart(hooks...)
+	}
+}
+
+// WithOnShutdown registers hooks that are run in order after the server has stopped listening. Use these to close databases, flush metrics or stop workers.
+func WithOnShutdown(hooks ...Hook) ServerOption {
+	return func(s *Server) {
+		s.OnShutdown(hooks...)
+	}
+}
+
+// WithAdminPort starts a second fiber app on the given port for metrics, pprof and health endpoints, keeping them off the public port. See AdminPort.
+func WithAdminPort(p int) ServerOption {
+	return func(s *Server) {
+		s.AdminPort(p)
+	}
+}
+
+// WithCursorSecret signs the cursor tokens of CursorPaginate with secret, shared by every instance so tokens survive restarts. Sets DefaultCursorSigner.
+func WithCursorSecret(secret []byte) ServerOption {
+	return func(s *Server) {
+		s.CursorSecret(secret)
+	}
+}
+
+// WithProblemDetails send every error response as an RFC 7807 application/problem+json document instead of the resp.ErrorResponse envelope.
+func WithProblemDetails() ServerOption {
+	return func(s *Server) {
+		s.UseProblemDetails()
+	}
+}
+
 // WithCatchAll sets up a simple catch all handler. This has to be a bool and used when Run() is called. If you set the catch all handler before the routes created by the application, everything will be caught. The bool removes this problem.
 func WithCatchAll() ServerOption {
 	return func(s *Server) {
@@ -149,13 +221,27 @@ func WithDefaultCORS() ServerOption {
 	}
 }
 
-// WithHealth opens up a health ping endpoint to be used for uptime monitoring.
+// WithHealth opens up the health endpoints to be used for uptime monitoring. See UseHealth.
 func WithHealth() ServerOption {
 	return func(s *Server) {
 		s.UseHealth()
 	}
 }
 
+// WithHealthCheck registers a named readiness check, e.g. health.Gorm(db) or health.Redis(client).
+func WithHealthCheck(name string, fn health.CheckFunc) ServerOption {
+	return func(s *Server) {
+		s.HealthCheck(name, fn)
+	}
+}
+
+// WithLivenessCheck registers a named liveness check. Liveness checks should only fail when the process needs to be restarted.
+func WithLivenessCheck(name string, fn health.CheckFunc) ServerOption {
+	return func(s *Server) {
+		s.LivenessCheck(name, fn)
+	}
+}
+
 // WithCache uses cache control headers to set the cache control header to public and max age to 1 year.
 func WithCache(cfg cache.Config) ServerOption {
 	return func(s *Server) {
@@ -170,30 +256,66 @@ func WithDefaultCache() ServerOption {
 	}
 }
 
-// Run start the fiber server. Should always be called instead of s.App().Listen(). Uses a graceful shutdown mechanism from https://github.com/gofiber/recipes/blob/7a04f52833b70b97251d8a37893d2e0c599a8c15/graceful-shutdown/main.go
+// Run start the fiber server and block until an interrupt or termination signal is received. Should always be called instead of s.App().Listen(). See RunContext for the shutdown behavior.
+// Exits when listening fails or the shutdown does not complete cleanly. Use RunContext to handle these errors yourself.
+func (s *Server) Run() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := s.RunContext(ctx); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// RunContext start the fiber server and block until the context is done or the listener fails. Uses a graceful shutdown mechanism from https://github.com/gofiber/recipes/blob/7a04f52833b70b97251d8a37893d2e0c599a8c15/graceful-shutdown/main.go
+//
+// Start hooks are run before listening. Once the context is done, open connections are drained within the shutdown timeout and the shutdown hooks are run. Errors from listening, draining and every hook are collected and returned as a MultiError.
 //
 // Catch all needs to be called here to not interfere with routing being created by the application.
-func (s *Server) Run() {
+func (s *Server) RunContext(ctx context.Context) error {
 	if s.catchAll {
 		if !s.pathExists("*") {
 			s.CatchAll()
 		}
 	}
 
-	go func() {
-		if err := s.app.Listen(fmt.Sprintf(":%d", s.port)); err != nil {
-			log.Panic(err)
+	var errs MultiError
+	if err := runHooks(ctx, s.startHooks, true); err != nil {
+		errs = append(errs, err...)
+	} else {
+		listenErr := make(chan error, 2)
+		listening := 1
+		go func() {
+			listenErr <- s.listen()
+		}()
+		if s.admin != nil {
+			listening++
+			go func() {
+				listenErr <- s.admin.Listen(fmt.Sprintf(":%d", s.adminPort))
+			}()
 		}
-	}()
 
-	c := make(chan os.Signal, 1)                    // Store channel to signify a signal being sent
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM) // When an interrupt or termination signal is sent, notify the channel
-
-	<-c // This blocks the main thread until an interrupt is received
-	log.Println("Gracefully shutting down...")
-	_ = s.app.Shutdown()
+		select {
+		case err := <-listenErr:
+			listening--
+			if err != nil {
+				errs = append(errs, err)
+			}
+			// one of the listeners failed, make sure the other one is stopped as well
+			errs = append(errs, s.shutdown()...)
+		case <-ctx.Done():
+			log.Println("Gracefully shutting down...")
+			errs = append(errs, s.shutdown()...)
+		}
+		errs = append(errs, s.awaitListeners(listenErr, listening)...)
+	}
 
 	log.Println("Running cleanup tasks...")
+	hookCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	errs = append(errs, runHooks(hookCtx, s.shutdownHooks, false)...)
+
+	return errs.ErrorOrNil()
 }
 
 // CatchAll helper function to automatically catch bad urls
@@ -212,6 +334,21 @@ func (s *Server) App() *fiber.App {
 	return s.app
 }
 
+// Health returns the health check registry used by the health endpoints.
+func (s *Server) Health() *health.Registry {
+	return s.healthChecks
+}
+
+// Prometheus returns the prometheus middleware, e.g. to export cache metrics. Returns nil when prometheus has not been enabled.
+func (s *Server) Prometheus() *middleware.Prometheus {
+	return s.prometheus
+}
+
+// Admin returns the admin fiber app instance. Returns nil when no admin port has been set.
+func (s *Server) Admin() *fiber.App {
+	return s.admin
+}
+
 // UseLogger use the logger middleware with a custom logger.Config struct. Use this when you need full control of the logger. The other logger helpers are designed to be called on their own.
 func (s *Server) UseLogger(cfg logger.Config) *Server {
 	s.app.Use(logger.New(cfg))
@@ -252,7 +389,51 @@ func (s *Server) Port(p int) *Server {
 	return s
 }
 
-// UsePrometheus helper function to set prometheus middleware.
+// ShutdownTimeout helper function to set how long the server waits for connections to drain and shutdown hooks to finish.
+func (s *Server) ShutdownTimeout(d time.Duration) *Server {
+	s.shutdownTimeout = d
+	return s
+}
+
+// CursorSecret helper function to sign the cursor tokens of CursorPaginate with secret. Sets DefaultCursorSigner, used by every server of the process.
+func (s *Server) CursorSecret(secret []byte) *Server {
+	DefaultCursorSigner = NewCursorSigner(secret)
+	return s
+}
+
+// OnStart helper function to register hooks that are run in order before the server starts listening.
+func (s *Server) OnStart(hooks ...Hook) *Server {
+	s.startHooks = append(s.startHooks, hooks...)
+	return s
+}
+
+// OnShutdown helper function to register hooks that are run in order after the server has stopped listening.
+func (s *Server) OnShutdown(hooks ...Hook) *Server {
+	s.shutdownHooks = append(s.shutdownHooks, hooks...)
+	return s
+}
+
+// AdminPort helper function to start a second fiber app on the given port for metrics, pprof and health endpoints.
+// Endpoints enabled before are moved to the admin app, and no longer served by the public app.
+func (s *Server) AdminPort(p int) *Server {
+	if s.admin == nil {
+		cfg := s.app.Config()
+		s.admin = fiber.New(fiber.Config{
+			AppName:               cfg.AppName,
+			Network:               cfg.Network,
+			DisableStartupMessage: cfg.DisableStartupMessage,
+			ReadTimeout:           cfg.ReadTimeout,
+			WriteTimeout:          cfg.WriteTimeout,
+		})
+		for _, mount := range s.operational {
+			mount(s.admin, false)
+		}
+	}
+	s.adminPort = p
+	return s
+}
+
+// UsePrometheus helper function to set prometheus middleware. The /metrics endpoint is mounted on the admin app when an admin port has been set, while the middleware always instruments the public app.
 func (s *Server) UsePrometheus(serviceName ...string) *Server {
 	sn := ToSnakeCase(s.app.Config().AppName)
 	if len(serviceName) > 1 {
@@ -260,8 +441,12 @@ func (s *Server) UsePrometheus(serviceName ...string) *Server {
 	}
 
 	prometheus := middleware.NewPrometheus(sn)
-	prometheus.RegisterAt(s.app, "/metrics")
+	s.mountOperational(func(app *fiber.App, public bool) {
+		app.Get("/metrics", s.operationalHandler(public, prometheus.Handler()))
+	})
 	s.app.Use(prometheus.Middleware)
+	s.healthChecks.SetObserver(prometheus)
+	s.prometheus = prometheus
 	return s
 }
 
@@ -272,7 +457,13 @@ func (s *Server) UsePprof(endpoint ...string) *Server {
 		cfg.Prefix = endpoint[0]
 	}
 
-	s.app.Use(pprof.New(cfg))
+	s.mountOperational(func(app *fiber.App, public bool) {
+		mounted := cfg
+		if public {
+			mounted.Next = s.movedToAdmin
+		}
+		app.Use(pprof.New(mounted))
+	})
 	return s
 }
 
@@ -300,17 +491,36 @@ func (s *Server) UseDefaultLimiter() *Server {
 	return s
 }
 
-// UseHealth opens up a health ping endpoint to be used for uptime monitoring.
+// UseHealth opens up the health endpoints to be used for uptime monitoring. Mounted on the admin app when an admin port has been set.
+//
+// /health/live runs the liveness checks, /health/ready and /health run the readiness checks. Each responds with a per-check breakdown and a http.StatusServiceUnavailable status code when a check fails.
 func (s *Server) UseHealth() *Server {
-	s.app.Get("/health", func(c *fiber.Ctx) error {
-		_ = c.SendStatus(http.StatusOK)
-		return c.JSON(&fiber.Map{
-			"message": "OK",
-		})
+	s.mountOperational(func(app *fiber.App, public bool) {
+		app.Get("/health", s.operationalHandler(public, s.healthChecks.ReadyHandler()))
+		app.Get("/health/live", s.operationalHandler(public, s.healthChecks.LiveHandler()))
+		app.Get("/health/ready", s.operationalHandler(public, s.healthChecks.ReadyHandler()))
 	})
 	return s
 }
 
+// HealthCheck helper function to register a named readiness check.
+func (s *Server) HealthCheck(name string, fn health.CheckFunc) *Server {
+	s.healthChecks.AddReadiness(name, fn)
+	return s
+}
+
+// LivenessCheck helper function to register a named liveness check.
+func (s *Server) LivenessCheck(name string, fn health.CheckFunc) *Server {
+	s.healthChecks.AddLiveness(name, fn)
+	return s
+}
+
+// UseProblemDetails send every error response as an RFC 7807 application/problem+json document instead of the resp.ErrorResponse envelope.
+func (s *Server) UseProblemDetails() *Server {
+	s.app.Use(resp.UseMode(resp.ModeProblem))
+	return s
+}
+
 // UseCache uses cache control headers to set the cache control header to public and max age to 1 year.
 func (s *Server) UseCache(cfg cache.Config) *Server {
 	s.app.Use(cache.New(cfg))
@@ -323,6 +533,113 @@ func (s *Server) UseDefaultCache() *Server {
 	return s
 }
 
+// listen starts serving on the configured port, Unix socket or listener, using TLS when it has been configured. Blocks until the server is shut down.
+// A Unix socket file is removed once serving stops, whether the server was shut down or failed.
+func (s *Server) listen() (err error) {
+	if !s.usesTLS() && !s.usesCustomListener() {
+		return s.app.Listen(fmt.Sprintf(":%d", s.port))
+	}
+
+	var cfg *tls.Config
+	if s.usesTLS() {
+		if cfg, err = s.buildTLSConfig(); err != nil {
+			return err
+		}
+	}
+
+	ln, err := s.netListener()
+	if err != nil {
+		return err
+	}
+	if s.listener == nil && s.unixSocket != "" {
+		defer func() {
+			if rmErr := s.removeUnixSocket(); rmErr != nil && err == nil {
+				err = rmErr
+			}
+		}()
+	}
+	if cfg != nil {
+		ln = tls.NewListener(ln, cfg)
+	}
+
+	return s.app.Listener(ln)
+}
+
+// awaitListeners waits for the listeners still running after the shutdown to return, so their cleanup is done, giving up after the shutdown timeout.
+func (s *Server) awaitListeners(listenErr chan error, listening int) MultiError {
+	var errs MultiError
+	timeout := time.After(s.shutdownTimeout)
+	for ; listening > 0; listening-- {
+		select {
+		case err := <-listenErr:
+			if err != nil {
+				errs = append(errs, err)
+			}
+		case <-timeout:
+			return append(errs, ErrShutdownTimeout)
+		}
+	}
+	return errs
+}
+
+// shutdown gracefully shuts down the fiber apps, giving up after the shutdown timeout.
+func (s *Server) shutdown() MultiError {
+	apps := []*fiber.App{s.app}
+	if s.admin != nil {
+		apps = append(apps, s.admin)
+	}
+
+	done := make(chan error, len(apps))
+	for _, app := range apps {
+		go func(app *fiber.App) {
+			done <- app.Shutdown()
+		}(app)
+	}
+
+	var errs MultiError
+	timeout := time.After(s.shutdownTimeout)
+	for range apps {
+		select {
+		case err := <-done:
+			if err != nil {
+				errs = append(errs, err)
+			}
+		case <-timeout:
+			return append(errs, ErrShutdownTimeout)
+		}
+	}
+	return errs
+}
+
+// mountOperational mounts metrics, pprof or health endpoints on the admin app when an admin port has been set, otherwise on the public app.
+// The mount is recorded, so AdminPort can move the endpoints to the admin app whatever the order of the options.
+func (s *Server) mountOperational(mount func(app *fiber.App, public bool)) {
+	s.operational = append(s.operational, mount)
+	if s.admin != nil {
+		mount(s.admin, false)
+	} else {
+		mount(s.app, true)
+	}
+}
+
+// operationalHandler guards the handler of an operational endpoint mounted on the public app, responding 404 once the endpoint has moved to the admin app.
+func (s *Server) operationalHandler(public bool, h fiber.Handler) fiber.Handler {
+	if !public {
+		return h
+	}
+	return func(c *fiber.Ctx) error {
+		if s.movedToAdmin(c) {
+			return fiber.ErrNotFound
+		}
+		return h(c)
+	}
+}
+
+// movedToAdmin checks if the operational endpoints have moved to the admin app.
+func (s *Server) movedToAdmin(_ *fiber.Ctx) bool {
+	return s.admin != nil
+}
+
 // pathExists scans the app route stack for a matching path.
 func (s *Server) pathExists(path string) bool {
 	found := false
@@ -2,10 +2,16 @@ package napi
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -98,6 +104,10 @@ func TestWithPrometheus_ExpectedBehavior(t *testing.T) {
 	if !s.methodAndPathExists("GET", "/metrics") {
 		t.Fatalf("should have found route: GET /metrics")
 	}
+
+	if s.Prometheus() == nil {
+		t.Fatal("should have exposed the prometheus middleware")
+	}
 }
 
 func TestWithLogger_ExpectedBehavior(t *testing.T) {
@@ -240,8 +250,223 @@ func TestWithHealth_ExpectedBehavior(t *testing.T) {
 		WithHealth(),
 	)
 
-	if s.app.HandlersCount() != 2 {
-		t.Fatalf("wanted 2 handlers (HEAD/GET), got: %d\n", s.app.HandlersCount())
+	if s.app.HandlersCount() != 6 {
+		t.Fatalf("wanted 6 handlers (HEAD/GET for /health, /health/live and /health/ready), got: %d\n", s.app.HandlersCount())
+	}
+}
+
+func TestWithHealthCheck_ShouldRespondUnavailableWhenCheckFails(t *testing.T) {
+	s := NewServer(
+		DefaultFiberConfig("test"),
+		WithHealth(),
+		WithHealthCheck("ok", func(ctx context.Context) error { return nil }),
+		WithHealthCheck("db", func(ctx context.Context) error { return errors.New("connection refused") }),
+	)
+
+	res, err := s.app.Test(httptest.NewRequest("GET", "/health/ready", nil), 15000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("wanted 503, got: %d\n", res.StatusCode)
+	}
+
+	body, _ := io.ReadAll(res.Body)
+	if !strings.Contains(string(body), `"error":"connection refused"`) {
+		t.Fatalf("should have found the failing check in:\n%s\n", string(body))
+	}
+
+	res, err = s.app.Test(httptest.NewRequest("GET", "/health/live", nil), 15000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("wanted 200 from the liveness endpoint, got: %d\n", res.StatusCode)
+	}
+}
+
+func TestRunContext_RunsHooksInOrder(t *testing.T) {
+	var calls []string
+	hook := func(name string) Hook {
+		return func(ctx context.Context) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	s := NewServer(
+		fiber.Config{DisableStartupMessage: true},
+		WithPort(testFreePort(t)),
+		WithOnStart(hook("start1"), hook("start2")),
+		WithOnShutdown(hook("shutdown1"), hook("shutdown2")),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := s.RunContext(ctx); err != nil {
+		t.Fatalf("unexpected error: %s\n", err)
+	}
+
+	want := []string{"start1", "start2", "shutdown1", "shutdown2"}
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Fatalf("wanted %v, got: %v\n", want, calls)
+	}
+}
+
+func TestRunContext_ReturnsListenError(t *testing.T) {
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	shutdownRan := false
+	s := NewServer(
+		fiber.Config{DisableStartupMessage: true},
+		WithPort(ln.Addr().(*net.TCPAddr).Port),
+		WithOnShutdown(func(ctx context.Context) error {
+			shutdownRan = true
+			return nil
+		}),
+	)
+
+	if err := s.RunContext(context.Background()); err == nil {
+		t.Fatal("should have returned the listen error")
+	}
+	if !shutdownRan {
+		t.Fatal("should have run the shutdown hooks")
+	}
+}
+
+func TestRunContext_CollectsHookErrors(t *testing.T) {
+	errStart := errors.New("start failed")
+	errShutdown := errors.New("shutdown failed")
+	secondStartRan := false
+
+	s := NewServer(
+		fiber.Config{DisableStartupMessage: true},
+		WithPort(testFreePort(t)),
+		WithOnStart(
+			func(ctx context.Context) error { return errStart },
+			func(ctx context.Context) error {
+				secondStartRan = true
+				return nil
+			},
+		),
+		WithOnShutdown(func(ctx context.Context) error { return errShutdown }),
+	)
+
+	err := s.RunContext(context.Background())
+	var errs MultiError
+	if !errors.As(err, &errs) {
+		t.Fatalf("wanted MultiError, got: %v\n", err)
+	}
+	if len(errs) != 2 || errs[0] != errStart || errs[1] != errShutdown {
+		t.Fatalf("wanted [%s %s], got: %v\n", errStart, errShutdown, errs)
+	}
+	if secondStartRan {
+		t.Fatal("should not have run start hooks after a failure")
+	}
+}
+
+func TestWithShutdownTimeout_ExpectedBehavior(t *testing.T) {
+	s := NewServer(DefaultFiberConfig("test"), WithShutdownTimeout(time.Second))
+	if s.shutdownTimeout != time.Second {
+		t.Fatalf("wanted shutdown timeout: 1s, got: %s\n", s.shutdownTimeout)
+	}
+}
+
+func TestWithAdminPort_MountsOperationalEndpointsOnAdminApp(t *testing.T) {
+	s := NewServer(
+		DefaultFiberConfig("admin_test"),
+		WithAdminPort(testFreePort(t)),
+		WithPrometheus(),
+		WithPprof(),
+		WithHealth(),
+	)
+
+	// Prometheus middleware still instruments the public app
+	if s.app.HandlersCount() != 1 {
+		t.Fatalf("wanted 1 handler on the public app, got: %d\n", s.app.HandlersCount())
+	}
+	if s.pathExists("/metrics") || s.pathExists("/health") {
+		t.Fatal("should not have mounted operational endpoints on the public app")
+	}
+	if !testRouteExists(s.Admin(), "GET", "/metrics") || !testRouteExists(s.Admin(), "GET", "/health") {
+		t.Fatal("should have mounted /metrics and /health on the admin app")
+	}
+}
+
+func TestWithAdminPort_ShouldMoveEndpointsEnabledBefore(t *testing.T) {
+	s := NewServer(
+		DefaultFiberConfig("admin_move_test"),
+		WithPrometheus(),
+		WithPprof(),
+		WithHealth(),
+		WithAdminPort(testFreePort(t)),
+	)
+
+	for _, path := range []string{"/metrics", "/debug/pprof/", "/health", "/health/ready"} {
+		res, err := s.app.Test(httptest.NewRequest("GET", path, nil), 15000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusNotFound {
+			t.Fatalf("wanted 404 for %s on the public app, got: %d\n", path, res.StatusCode)
+		}
+
+		res, err = s.Admin().Test(httptest.NewRequest("GET", path, nil), 15000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("wanted 200 for %s on the admin app, got: %d\n", path, res.StatusCode)
+		}
+	}
+}
+
+func TestWithAdminPort_ServesAlongsidePublicApp(t *testing.T) {
+	port, adminPort := testFreePort(t), testFreePort(t)
+	s := NewServer(
+		fiber.Config{DisableStartupMessage: true},
+		WithPort(port),
+		WithAdminPort(adminPort),
+		WithHealth(),
+	)
+	stop := testRunServer(t, s, "tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	defer stop()
+	testWaitForDial(t, "tcp", fmt.Sprintf("127.0.0.1:%d", adminPort), nil)
+
+	res, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/health", adminPort))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("wanted 200 from the admin app, got: %d\n", res.StatusCode)
+	}
+
+	res, err = http.Get(fmt.Sprintf("http://127.0.0.1:%d/health", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("wanted 404 from the public app, got: %d\n", res.StatusCode)
+	}
+}
+
+func TestWithProblemDetails_ExpectedBehavior(t *testing.T) {
+	cfg := DefaultFiberConfig("test")
+	cfg.ErrorHandler = ErrorHandler
+	s := NewServer(cfg, WithProblemDetails())
+
+	res, err := s.app.Test(httptest.NewRequest("GET", "/fail", nil), 15000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Header.Get(fiber.HeaderContentType) != "application/problem+json" {
+		t.Fatalf("wanted application/problem+json, got: %s\n", res.Header.Get(fiber.HeaderContentType))
 	}
 }
 
@@ -282,6 +507,8 @@ func ExampleNewServer() {
 		WithDefaultLimiter(),
 		WithLimiter(limiter.Config{}),
 		WithDefaultCache(),
+		WithShutdownTimeout(10*time.Second),
+		WithOnShutdown(func(ctx context.Context) error { return nil }),
 	)
 }
 
@@ -314,3 +541,24 @@ func testPprofRequest(t *testing.T, s *Server, path string) []byte {
 
 	return body
 }
+
+func testFreePort(t *testing.T) int {
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("finding free port: %s\n", err)
+	}
+	defer ln.Close()
+
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func testRouteExists(app *fiber.App, method, path string) bool {
+	for _, routes := range app.Stack() {
+		for _, route := range routes {
+			if route.Method == method && route.Path == path {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package napi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testSortModel struct {
+	ID        uint
+	Username  string
+	Age       int
+	LastLogin *int
+}
+
+func testSortRules() SortRules {
+	return SortRules{
+		"age":        {},
+		"name":       {Column: "username"},
+		"last_login": {Column: "last_login", Nulls: NullsLast},
+	}
+}
+
+func testSeedSortModels(t *testing.T) *gorm.DB {
+	db := testGormDB(t, &testSortModel{})
+	one, two := 1, 2
+	models := []testSortModel{
+		{Username: "alice", Age: 30, LastLogin: &two},
+		{Username: "bob", Age: 20},
+		{Username: "carol", Age: 30, LastLogin: &one},
+		{Username: "dave", Age: 20, LastLogin: &one},
+	}
+	if err := db.Create(&models).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func testSortUsernames(t *testing.T, db *gorm.DB, req *CanOrder) []string {
+	var items []testSortModel
+	if err := db.Scopes(req.SortScope(testSortRules())).Find(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Username
+	}
+	return names
+}
+
+func TestCanOrder_SortKeys(t *testing.T) {
+	req := &CanOrder{Sort: "-created_at, username,,+age,-username"}
+	assert.Equal(t, []SortKey{{Key: "created_at", Desc: true}, {Key: "username"}, {Key: "age"}}, req.SortKeys())
+
+	req = &CanOrder{OrderBy: "age", OrderDir: "desc"}
+	assert.Equal(t, []SortKey{{Key: "age", Desc: true}}, req.SortKeys())
+}
+
+func TestCanOrder_SortScope_MultipleColumns(t *testing.T) {
+	db := testSeedSortModels(t)
+
+	assert.Equal(t, []string{"carol", "alice", "dave", "bob"}, testSortUsernames(t, db, &CanOrder{Sort: "-age,-name"}))
+	assert.Equal(t, []string{"bob", "dave", "alice", "carol"}, testSortUsernames(t, db, &CanOrder{Sort: "age,name"}))
+}
+
+func TestCanOrder_SortScope_NullsLast(t *testing.T) {
+	db := testSeedSortModels(t)
+
+	assert.Equal(t, []string{"alice", "carol", "dave", "bob"}, testSortUsernames(t, db, &CanOrder{Sort: "-last_login,name"}))
+	assert.Equal(t, []string{"carol", "dave", "alice", "bob"}, testSortUsernames(t, db, &CanOrder{Sort: "last_login,name"}))
+}
+
+func TestCanOrder_SortScope_ShouldSkipUnknownKeys(t *testing.T) {
+	db := testSeedSortModels(t)
+
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&testSortModel{}).Scopes((&CanOrder{Sort: "password,-name"}).SortScope(testSortRules())).Find(&[]testSortModel{})
+	})
+	assert.Equal(t, "SELECT * FROM `test_sort_models` ORDER BY `username` DESC", sql)
+}
+
+func TestCanOrder_CheckSort(t *testing.T) {
+	assert.Nil(t, (&CanOrder{Sort: "-age,name"}).CheckSort(testSortRules()))
+
+	err := (&CanOrder{Sort: "-age,password,username"}).CheckSort(testSortRules())
+	if err == nil {
+		t.Fatal("should have returned a validation error")
+	}
+	assert.Equal(t, map[string]string{"sort": "cannot sort by password, username"}, err.Error())
+}
+
+func TestPaginate_ShouldUseSortRules(t *testing.T) {
+	db := testSeedSortModels(t)
+	p := &Paginater{CanOrder: CanOrder{Sort: "-age,name"}}
+
+	items, _, err := Paginate[testSortModel](db, p, ScopeOptions{Sort: testSortRules()})
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", items[0].Username)
+}
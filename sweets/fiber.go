@@ -11,9 +11,9 @@ type FiberSuite struct {
 	router fiber.Router
 }
 
-// NewFiberSuite is used to instantiate a new fiber.App test suite. Typically called in SetupSuite() while testing controllers.
-func (suite *FiberSuite) NewFiberSuite(baseUrl string) *fiber.App {
-	app := fiber.New()
+// NewFiberSuite is used to instantiate a new fiber.App test suite. Typically called in SetupSuite() while testing controllers. An optional fiber.Config can be given, e.g. to use the same ErrorHandler as the application.
+func (suite *FiberSuite) NewFiberSuite(baseUrl string, cfg ...fiber.Config) *fiber.App {
+	app := fiber.New(cfg...)
 	api := app.Group(baseUrl)
 
 	suite.app = app
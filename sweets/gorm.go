@@ -2,26 +2,38 @@ package sweets
 
 import (
 	"database/sql"
+	"fmt"
+	"github.com/netr/napi/factory"
+	"github.com/netr/napi/migrate"
+	"github.com/netr/napi/seed"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	glog "gorm.io/gorm/logger"
 	"log"
 	"reflect"
+	"sync/atomic"
 	"testing"
 )
 
+// memoryDatabases numbers the in-memory databases, giving every suite its own.
+var memoryDatabases int64
+
 type GormSuite struct {
 	db         *gorm.DB
 	ranOnce    bool
 	migrations []interface{}
+	migrator   *migrate.Migrator
+	factory    *factory.Factory
+	pool       *isolatedPool
 }
 
 // NewGormSuite is used to instantiate a new gorm.DB test suite. Typically called in SetupSuite().
+// Every suite gets its own in-memory database, so suites running in parallel don't see each other's rows.
 // We can leverage log.Fatal here, since this method is only used in testing, removing verbosity from our test files.
 func (suite *GormSuite) NewGormSuite(migrations ...interface{}) {
 	db, err := gorm.Open(
-		sqlite.Open("file::memory:?cache=shared"),
+		sqlite.Open(fmt.Sprintf("file:napi_%d?mode=memory&cache=shared", atomic.AddInt64(&memoryDatabases, 1))),
 		&gorm.Config{
 			Logger: glog.Default.LogMode(glog.Silent),
 		})
@@ -40,11 +52,57 @@ func (suite *GormSuite) NewGormSuite(migrations ...interface{}) {
 	return
 }
 
+// NewGormSuiteWithMigrations is like NewGormSuite, but builds the schema with versioned migrations instead of AutoMigrate. RefreshDB then resets and re-runs them.
+// We can leverage log.Fatal here, since this method is only used in testing, removing verbosity from our test files.
+func (suite *GormSuite) NewGormSuiteWithMigrations(migrations ...migrate.Migration) {
+	suite.NewGormSuite()
+
+	suite.migrator = migrate.New(suite.db, migrations)
+	if err := suite.migrator.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+	return
+}
+
+// UseTransactions makes RefreshDB begin a transaction for the test instead of rebuilding the schema, and RollbackDB roll it back.
+// Transactions started by the code under test become savepoints of the test's transaction.
+// Call it before handing DB() to routes or factories, since only the *gorm.DB's created afterwards go through the test's transaction.
+// We can leverage log.Fatal here, since this method is only used in testing, removing verbosity from our test files.
+func (suite *GormSuite) UseTransactions() {
+	sqlDB, err := suite.db.DB()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.pool = &isolatedPool{db: sqlDB}
+	suite.db.ConnPool = suite.pool
+	suite.db.Statement.ConnPool = suite.pool
+	return
+}
+
 // RefreshDB will drop all your current migrations and re-migrate with a fresh database. Used in SetupTest().
+// Suites created with NewGormSuiteWithMigrations roll back every migration and run them again instead.
+// Suites using transactions roll back the previous test's transaction, if still open, and begin a new one instead.
 // We can leverage log.Fatal here, since this method is only used in testing, removing verbosity from our test files.
 func (suite *GormSuite) RefreshDB() {
-	if suite.ranOnce {
-		err := suite.db.Migrator().DropTable(suite.migrations...)
+	if suite.pool != nil {
+		if err := suite.pool.begin(); err != nil {
+			log.Fatal(err)
+		}
+	} else if suite.ranOnce && suite.migrator != nil {
+		if err := suite.migrator.Reset(); err != nil {
+			log.Fatal(err)
+		}
+
+		if err := suite.db.Migrator().DropTable(seed.DefaultTable); err != nil {
+			log.Fatal(err)
+		}
+
+		if err := suite.migrator.Migrate(); err != nil {
+			log.Fatal(err)
+		}
+	} else if suite.ranOnce {
+		err := suite.db.Migrator().DropTable(append(suite.migrations, seed.DefaultTable)...)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -60,6 +118,32 @@ func (suite *GormSuite) RefreshDB() {
 	return
 }
 
+// RollbackDB rolls back the test's transaction of a suite using transactions. Used in TearDownTest().
+// We can leverage log.Fatal here, since this method is only used in testing, removing verbosity from our test files.
+func (suite *GormSuite) RollbackDB() {
+	if suite.pool == nil {
+		return
+	}
+
+	if err := suite.pool.rollback(); err != nil {
+		log.Fatal(err)
+	}
+	return
+}
+
+// UseFactory sets the factory used by Seed to create models. Defaults to an empty factory.
+func (suite *GormSuite) UseFactory(f *factory.Factory) {
+	suite.factory = f
+}
+
+// Seed runs the seeders and their dependencies in the "test" environment.
+// We can leverage log.Fatal here, since this method is only used in testing, removing verbosity from our test files.
+func (suite *GormSuite) Seed(seeders ...seed.Seeder) {
+	if err := seed.New(suite.db, suite.factory, seed.WithEnv("test")).Run(seeders...); err != nil {
+		log.Fatal(err)
+	}
+}
+
 // DB is a helper function to get the underlying *gorm.DB
 func (suite *GormSuite) DB() *gorm.DB {
 	return suite.db
@@ -76,6 +160,8 @@ func (suite *GormSuite) Sql() *sql.DB {
 
 // ShutdownDB is a helper function to shut down the underlying *gorm.DB
 func (suite *GormSuite) ShutdownDB() {
+	suite.RollbackDB()
+
 	if d, err := suite.DB().DB(); err != nil {
 		return
 	} else {
@@ -0,0 +1,83 @@
+package sweets
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type testWidget struct {
+	ID   uint
+	Name string
+}
+
+func testGormSuite(t *testing.T, transactions bool) *GormSuite {
+	suite := &GormSuite{}
+	suite.NewGormSuite(&testWidget{})
+	if transactions {
+		suite.UseTransactions()
+	}
+	t.Cleanup(suite.ShutdownDB)
+	suite.RefreshDB()
+	return suite
+}
+
+func TestGormSuite_ShouldUseItsOwnDatabase(t *testing.T) {
+	for _, name := range []string{"first", "second"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			suite := testGormSuite(t, false)
+
+			for i := 0; i < 3; i++ {
+				suite.DB().Create(&testWidget{Name: name})
+			}
+			suite.AssertDatabaseCount(t, &testWidget{}, 3)
+		})
+	}
+}
+
+func TestGormSuite_UseTransactions(t *testing.T) {
+	suite := testGormSuite(t, true)
+	db := suite.DB()
+
+	db.Create(&testWidget{Name: "created"})
+	assert.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(&testWidget{Name: "committed"}).Error
+	}))
+	assert.Error(t, db.Transaction(func(tx *gorm.DB) error {
+		tx.Create(&testWidget{Name: "rolled back"})
+		return errors.New("failed")
+	}))
+	suite.AssertDatabaseCount(t, &testWidget{}, 2)
+	suite.AssertDatabaseMissing(t, &testWidget{Name: "rolled back"})
+
+	suite.RollbackDB()
+	suite.AssertDatabaseCount(t, &testWidget{}, 0)
+
+	suite.RefreshDB()
+	db.Create(&testWidget{Name: "created"})
+	suite.RefreshDB()
+	suite.AssertDatabaseCount(t, &testWidget{}, 0)
+}
+
+func TestGormSuite_UseTransactions_ShouldNestTransactions(t *testing.T) {
+	suite := testGormSuite(t, true)
+	db := suite.DB()
+
+	tx := db.Begin()
+	tx.Create(&testWidget{Name: "outer"})
+	assert.Error(t, tx.Transaction(func(tx *gorm.DB) error {
+		tx.Create(&testWidget{Name: "inner"})
+		return errors.New("failed")
+	}))
+	assert.NoError(t, tx.Commit().Error)
+
+	suite.AssertDatabaseHas(t, &testWidget{Name: "outer"})
+	suite.AssertDatabaseMissing(t, &testWidget{Name: "inner"})
+
+	suite.RollbackDB()
+	suite.AssertDatabaseCount(t, &testWidget{}, 0)
+}
@@ -0,0 +1,118 @@
+package sweets
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// isolatedPool is the gorm.ConnPool of a suite using transactions. While a test runs, every query goes through the test's transaction,
+// and transactions started by the code under test become savepoints, so rolling back the test's transaction undoes all of them.
+type isolatedPool struct {
+	db *sql.DB
+
+	lock       sync.RWMutex
+	tx         *sql.Tx
+	savepoints int
+}
+
+// begin rolls back the current test's transaction, if any, and begins a new one.
+func (p *isolatedPool) begin() error {
+	if err := p.rollback(); err != nil {
+		return err
+	}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.tx = tx
+	return nil
+}
+
+// rollback rolls back the current test's transaction, if any.
+func (p *isolatedPool) rollback() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.tx == nil {
+		return nil
+	}
+	err := p.tx.Rollback()
+	p.tx = nil
+	return err
+}
+
+// conn returns the current test's transaction, or the database between tests.
+func (p *isolatedPool) conn() gorm.ConnPool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.tx == nil {
+		return p.db
+	}
+	return p.tx
+}
+
+func (p *isolatedPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.conn().PrepareContext(ctx, query)
+}
+
+func (p *isolatedPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return p.conn().ExecContext(ctx, query, args...)
+}
+
+func (p *isolatedPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return p.conn().QueryContext(ctx, query, args...)
+}
+
+func (p *isolatedPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return p.conn().QueryRowContext(ctx, query, args...)
+}
+
+// BeginTx starts a savepoint in the current test's transaction, or a real transaction between tests.
+func (p *isolatedPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.tx == nil {
+		return p.db.BeginTx(ctx, opts)
+	}
+
+	p.savepoints++
+	// a prefix of its own, so the savepoint never shares a name with the ones of napi.Tx
+	sp := &savepoint{Tx: p.tx, name: fmt.Sprintf("napi_test_sp%d", p.savepoints)}
+	if _, err := p.tx.ExecContext(ctx, "SAVEPOINT "+sp.name); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+// GetDBConn returns the underlying *sql.DB, e.g. for gorm.DB.DB().
+func (p *isolatedPool) GetDBConn() (*sql.DB, error) {
+	return p.db, nil
+}
+
+// savepoint is a transaction started inside the test's transaction. Commit releases it and Rollback rolls back to it.
+type savepoint struct {
+	*sql.Tx
+	name string
+}
+
+func (sp *savepoint) Commit() error {
+	_, err := sp.Tx.Exec("RELEASE SAVEPOINT " + sp.name)
+	return err
+}
+
+func (sp *savepoint) Rollback() error {
+	if _, err := sp.Tx.Exec("ROLLBACK TO SAVEPOINT " + sp.name); err != nil {
+		return err
+	}
+	return sp.Commit()
+}
@@ -0,0 +1,102 @@
+package napi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClientSubjectKey is the fiber.Ctx locals key holding the verified client certificate subject once ClientSubject has read it from the connection.
+const ClientSubjectKey = "napi.client_subject"
+
+// WithTLS serve HTTPS using the given certificate and key files. The files are loaded when the server starts, so errors are returned from Run.
+func WithTLS(certFile, keyFile string) ServerOption {
+	return func(s *Server) {
+		s.UseTLS(certFile, keyFile)
+	}
+}
+
+// WithTLSConfig serve HTTPS using a custom tls.Config. Can be combined with WithTLS to load the certificate from files.
+func WithTLSConfig(cfg *tls.Config) ServerOption {
+	return func(s *Server) {
+		s.UseTLSConfig(cfg)
+	}
+}
+
+// WithMutualTLS require clients to present a certificate signed by one of the given CAs. The verified subject is available to handlers through ClientSubject.
+func WithMutualTLS(caPool *x509.CertPool) ServerOption {
+	return func(s *Server) {
+		s.UseMutualTLS(caPool)
+	}
+}
+
+// UseTLS helper function to serve HTTPS using the given certificate and key files.
+func (s *Server) UseTLS(certFile, keyFile string) *Server {
+	s.tlsCertFile = certFile
+	s.tlsKeyFile = keyFile
+	return s
+}
+
+// UseTLSConfig helper function to serve HTTPS using a custom tls.Config.
+func (s *Server) UseTLSConfig(cfg *tls.Config) *Server {
+	s.tlsConfig = cfg
+	return s
+}
+
+// UseMutualTLS helper function to require and verify client certificates against the given CAs.
+// The CAs are applied to a clone of the tls.Config when the server starts, so the order of the TLS options doesn't matter.
+func (s *Server) UseMutualTLS(caPool *x509.CertPool) *Server {
+	s.clientCAs = caPool
+	return s
+}
+
+// ClientSubject returns the verified client certificate subject of the request. Only available when mutual TLS is enabled.
+// The subject is read from the TLS connection, so it is available to every handler and middleware, wherever they are registered.
+func ClientSubject(c *fiber.Ctx) (pkix.Name, bool) {
+	if subject, ok := c.Locals(ClientSubjectKey).(pkix.Name); ok {
+		return subject, true
+	}
+
+	state := c.Context().TLSConnectionState()
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return pkix.Name{}, false
+	}
+	subject := state.VerifiedChains[0][0].Subject
+	c.Locals(ClientSubjectKey, subject)
+	return subject, true
+}
+
+// usesTLS checks if any of the TLS options have been set.
+func (s *Server) usesTLS() bool {
+	return s.tlsConfig != nil || s.tlsCertFile != "" || s.clientCAs != nil
+}
+
+// buildTLSConfig clones the configured tls.Config, loads the certificate files and applies the mutual TLS CAs to it.
+func (s *Server) buildTLSConfig() (*tls.Config, error) {
+	cfg := &tls.Config{}
+	if s.tlsConfig != nil {
+		cfg = s.tlsConfig.Clone()
+	}
+
+	if s.tlsCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(s.tlsCertFile, s.tlsKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+
+	if s.clientCAs != nil {
+		cfg.ClientCAs = s.clientCAs
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	if len(cfg.Certificates) == 0 && cfg.GetCertificate == nil {
+		return nil, errors.New("tls: no certificate configured")
+	}
+
+	return cfg, nil
+}
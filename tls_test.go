@@ -0,0 +1,262 @@
+package napi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestWithTLS_ExpectedBehavior(t *testing.T) {
+	ca := newTestCA(t)
+	certFile, keyFile := ca.writeCert(t, "localhost", true)
+
+	port := testFreePort(t)
+	s := NewServer(
+		fiber.Config{DisableStartupMessage: true},
+		WithPort(port),
+		WithTLS(certFile, keyFile),
+	)
+	s.App().Get("/ping", func(c *fiber.Ctx) error {
+		return c.SendString("pong")
+	})
+	stop := testRunServer(t, s, "tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	defer stop()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: ca.pool()}}}
+	body := testHTTPGet(t, client, fmt.Sprintf("https://localhost:%d/ping", port))
+	if body != "pong" {
+		t.Fatalf("wanted pong, got: %s\n", body)
+	}
+}
+
+func TestWithTLS_ShouldReturnErrorWhenFilesAreMissing(t *testing.T) {
+	s := NewServer(
+		fiber.Config{DisableStartupMessage: true},
+		WithPort(testFreePort(t)),
+		WithTLS("missing.pem", "missing.key"),
+	)
+
+	if err := s.RunContext(context.Background()); err == nil {
+		t.Fatal("should have returned an error for the missing certificate files")
+	}
+}
+
+func TestWithMutualTLS_ExposesClientSubject(t *testing.T) {
+	ca := newTestCA(t)
+	serverCert := ca.issue(t, "localhost", true)
+	clientCert := ca.issue(t, "test-client", false)
+
+	whoami := func(s *Server) {
+		s.App().Get("/whoami", func(c *fiber.Ctx) error {
+			subject, ok := ClientSubject(c)
+			if !ok {
+				return c.SendStatus(http.StatusUnauthorized)
+			}
+			return c.SendString(subject.CommonName)
+		})
+	}
+
+	tests := []struct {
+		name string
+		opts func(cfg *tls.Config) []ServerOption
+	}{
+		{"tls config first", func(cfg *tls.Config) []ServerOption {
+			return []ServerOption{WithTLSConfig(cfg), WithMutualTLS(ca.pool()), whoami}
+		}},
+		{"mutual tls first", func(cfg *tls.Config) []ServerOption {
+			return []ServerOption{WithMutualTLS(ca.pool()), WithTLSConfig(cfg), whoami}
+		}},
+		{"mutual tls after routes", func(cfg *tls.Config) []ServerOption {
+			return []ServerOption{WithTLSConfig(cfg), whoami, WithMutualTLS(ca.pool())}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &tls.Config{Certificates: []tls.Certificate{serverCert}}
+			port := testFreePort(t)
+			s := NewServer(
+				fiber.Config{DisableStartupMessage: true},
+				append([]ServerOption{WithPort(port)}, tt.opts(cfg)...)...,
+			)
+			stop := testRunServer(t, s, "tcp", fmt.Sprintf("127.0.0.1:%d", port))
+			defer stop()
+
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+				RootCAs:      ca.pool(),
+				Certificates: []tls.Certificate{clientCert},
+			}}}
+			body := testHTTPGet(t, client, fmt.Sprintf("https://localhost:%d/whoami", port))
+			if body != "test-client" {
+				t.Fatalf("wanted test-client, got: %s\n", body)
+			}
+
+			anonymous := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: ca.pool()}}}
+			if _, err := anonymous.Get(fmt.Sprintf("https://localhost:%d/whoami", port)); err == nil {
+				t.Fatal("should have rejected a client without a certificate")
+			}
+
+			if cfg.ClientCAs != nil || cfg.ClientAuth != tls.NoClientCert {
+				t.Fatal("should not have modified the given tls.Config")
+			}
+		})
+	}
+}
+
+// testCA is a self-signed certificate authority generated at test time.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "napi test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+func (ca *testCA) issueDER(t *testing.T, commonName string, isServer bool) ([]byte, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if isServer {
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		tmpl.DNSNames = []string{commonName}
+		tmpl.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der, key
+}
+
+func (ca *testCA) issue(t *testing.T, commonName string, isServer bool) tls.Certificate {
+	der, key := ca.issueDER(t, commonName, isServer)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func (ca *testCA) writeCert(t *testing.T, commonName string, isServer bool) (string, string) {
+	der, key := ca.issueDER(t, commonName, isServer)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return certFile, keyFile
+}
+
+// testRunServer runs the server in the background and waits for the address to accept connections. The returned func stops the server.
+func testRunServer(t *testing.T, s *Server, network, addr string) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.RunContext(ctx)
+	}()
+
+	testWaitForDial(t, network, addr, done)
+
+	return func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Fatalf("running server: %s\n", err)
+		}
+	}
+}
+
+// testWaitForDial waits until the address accepts connections, failing early if the server stops running.
+func testWaitForDial(t *testing.T, network, addr string, done chan error) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-done:
+			t.Fatalf("server stopped early: %v\n", err)
+		default:
+		}
+
+		conn, err := net.Dial(network, addr)
+		if err == nil {
+			_ = conn.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("server never started listening on %s\n", addr)
+}
+
+func testHTTPGet(t *testing.T, client *http.Client, url string) string {
+	res, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("requesting %s: %s\n", url, err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("reading body: %s\n", err)
+	}
+	return string(body)
+}
@@ -0,0 +1,121 @@
+package napi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// TxKey is the fiber.Ctx locals key holding the Tx of a request wrapped by Transactional.
+const TxKey = "napi.tx"
+
+// errRollbackResponse rolls back the transaction of a request that sent an error response without returning an error.
+var errRollbackResponse = errors.New("rollback error response")
+
+// Tx is a database transaction. It implements IDatabaseDriver[*gorm.DB], so repositories created with NewRepository[T](tx) run inside the transaction.
+type Tx interface {
+	IDatabaseDriver[*gorm.DB]
+	// Transaction runs fn in a savepoint, rolled back on error or panic without aborting the outer transaction.
+	Transaction(fn func(tx Tx) error) error
+}
+
+// gormTx a Tx on top of a *gorm.DB transaction.
+type gormTx struct {
+	db *gorm.DB
+}
+
+// savepoints numbers the savepoints of nested transactions, keeping their names unique even when the transaction is itself a savepoint of a caller.
+var savepoints int64
+
+// WithTransaction runs fn in a transaction, committed when fn returns nil and rolled back when it returns an error or panics. Panics are re-raised after the rollback.
+//
+//	err := napi.WithTransaction(ctx, db, func(tx napi.Tx) error {
+//		return napi.NewRepository[Account](tx).Create(acc)
+//	})
+func WithTransaction(ctx context.Context, db *gorm.DB, fn func(tx Tx) error) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&gormTx{db: tx})
+	})
+}
+
+// DB returns the *gorm.DB bound to the transaction.
+func (t *gormTx) DB() *gorm.DB {
+	return t.db
+}
+
+// Transaction runs fn in a savepoint, released when fn returns nil and rolled back to when it returns an error or panics.
+func (t *gormTx) Transaction(fn func(tx Tx) error) (err error) {
+	name := fmt.Sprintf("napi_sp%d", atomic.AddInt64(&savepoints, 1))
+	if err = t.db.SavePoint(name).Error; err != nil {
+		return err
+	}
+
+	panicked := true
+	defer func() {
+		if !panicked && err == nil {
+			err = t.releaseSavePoint(name)
+			return
+		}
+
+		rbErr := t.db.RollbackTo(name).Error
+		if rbErr == nil {
+			rbErr = t.releaseSavePoint(name)
+		}
+		if rbErr != nil && !panicked {
+			err = fmt.Errorf("%w (rolling back to %s: %v)", err, name, rbErr)
+		}
+	}()
+
+	err = fn(&gormTx{db: t.db})
+	panicked = false
+	return err
+}
+
+// releaseSavePoint removes the savepoint from the transaction, keeping its changes.
+func (t *gormTx) releaseSavePoint(name string) error {
+	return t.db.Exec("RELEASE SAVEPOINT " + name).Error
+}
+
+// Transactional is a middleware wrapping the request in a transaction, opted in per route. The transaction is rolled back when the handler returns an error, panics or sends a status code of 400 or above.
+//
+//	app.Post("/accounts", napi.Transactional(db), ctrl.Store())
+func Transactional(db *gorm.DB) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := WithTransaction(c.UserContext(), db, func(tx Tx) error {
+			c.Locals(TxKey, tx)
+			defer c.Locals(TxKey, nil)
+
+			if err := c.Next(); err != nil {
+				return err
+			}
+			if c.Response().StatusCode() >= http.StatusBadRequest {
+				return errRollbackResponse
+			}
+			return nil
+		})
+
+		if errors.Is(err, errRollbackResponse) {
+			return nil
+		}
+		return err
+	}
+}
+
+// TxFrom returns the transaction of a request wrapped by Transactional.
+func TxFrom(c *fiber.Ctx) (Tx, bool) {
+	tx, ok := c.Locals(TxKey).(Tx)
+	return tx, ok
+}
+
+// DriverFrom returns the transaction of the request when wrapped by Transactional, or db otherwise. Lets handlers create repositories without knowing whether the route is transactional.
+func DriverFrom(c *fiber.Ctx, db *gorm.DB) IDatabaseDriver[*gorm.DB] {
+	if tx, ok := TxFrom(c); ok {
+		return tx
+	}
+	return NewGormRepository(db)
+}
@@ -0,0 +1,194 @@
+package napi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/netr/napi/resp"
+	"github.com/netr/napi/sweets"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func testTxCount(t *testing.T, db *gorm.DB) int64 {
+	count, err := NewRepository[testRepoModel](NewGormRepository(db)).Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestWithTransaction_ShouldCommit(t *testing.T) {
+	db := testGormDB(t, &testRepoModel{})
+
+	err := WithTransaction(context.Background(), db, func(tx Tx) error {
+		return NewRepository[testRepoModel](tx).Create(&testRepoModel{Username: "alice"})
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), testTxCount(t, db))
+}
+
+func TestWithTransaction_ShouldRollbackOnError(t *testing.T) {
+	db := testGormDB(t, &testRepoModel{})
+	failed := errors.New("failed")
+
+	err := WithTransaction(context.Background(), db, func(tx Tx) error {
+		if err := NewRepository[testRepoModel](tx).Create(&testRepoModel{Username: "alice"}); err != nil {
+			return err
+		}
+		return failed
+	})
+
+	assert.ErrorIs(t, err, failed)
+	assert.Equal(t, int64(0), testTxCount(t, db))
+}
+
+func TestWithTransaction_ShouldRollbackOnPanic(t *testing.T) {
+	db := testGormDB(t, &testRepoModel{})
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = WithTransaction(context.Background(), db, func(tx Tx) error {
+			_ = NewRepository[testRepoModel](tx).Create(&testRepoModel{Username: "alice"})
+			panic("boom")
+		})
+	})
+	assert.Equal(t, int64(0), testTxCount(t, db))
+}
+
+func TestTx_Transaction_ShouldRollbackToSavepoint(t *testing.T) {
+	db := testGormDB(t, &testRepoModel{})
+
+	err := WithTransaction(context.Background(), db, func(tx Tx) error {
+		repo := NewRepository[testRepoModel](tx)
+		if err := repo.Create(&testRepoModel{Username: "alice"}); err != nil {
+			return err
+		}
+
+		nestedErr := tx.Transaction(func(nested Tx) error {
+			if err := NewRepository[testRepoModel](nested).Create(&testRepoModel{Username: "bob"}); err != nil {
+				return err
+			}
+			return nested.Transaction(func(deeper Tx) error {
+				return NewRepository[testRepoModel](deeper).Create(&testRepoModel{Username: "alice"})
+			})
+		})
+		assert.Error(t, nestedErr)
+
+		return tx.Transaction(func(nested Tx) error {
+			return NewRepository[testRepoModel](nested).Create(&testRepoModel{Username: "carol"})
+		})
+	})
+
+	assert.NoError(t, err)
+	models, _ := NewRepository[testRepoModel](NewGormRepository(db)).FindBy()
+	assert.Len(t, models, 2)
+	assert.Equal(t, "carol", models[1].Username)
+}
+
+func TestTx_Transaction_ShouldReleaseUniqueSavepoints(t *testing.T) {
+	db := testGormDB(t, &testRepoModel{})
+	var statements []string
+	_ = db.Callback().Raw().After("gorm:raw").Register("test:savepoints", func(db *gorm.DB) {
+		statements = append(statements, db.Statement.SQL.String())
+	})
+
+	err := WithTransaction(context.Background(), db, func(tx Tx) error {
+		for _, name := range []string{"alice", "bob"} {
+			name := name
+			if err := tx.Transaction(func(nested Tx) error {
+				return NewRepository[testRepoModel](nested).Create(&testRepoModel{Username: name})
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), testTxCount(t, db))
+
+	if assert.Len(t, statements, 4) {
+		first, second := strings.TrimPrefix(statements[0], "SAVEPOINT "), strings.TrimPrefix(statements[2], "SAVEPOINT ")
+		assert.NotEqual(t, first, second)
+		assert.Equal(t, []string{"SAVEPOINT " + first, "RELEASE SAVEPOINT " + first, "SAVEPOINT " + second, "RELEASE SAVEPOINT " + second}, statements)
+	}
+}
+
+func TestWithTransaction_ShouldRollbackInIsolatedGormSuite(t *testing.T) {
+	suite := &sweets.GormSuite{}
+	suite.NewGormSuite(&testRepoModel{})
+	suite.UseTransactions()
+	t.Cleanup(suite.ShutdownDB)
+	suite.RefreshDB()
+
+	err := WithTransaction(context.Background(), suite.DB(), func(tx Tx) error {
+		if err := NewRepository[testRepoModel](tx).Create(&testRepoModel{Username: "alice"}); err != nil {
+			return err
+		}
+		if err := tx.Transaction(func(nested Tx) error {
+			return NewRepository[testRepoModel](nested).Create(&testRepoModel{Username: "bob"})
+		}); err != nil {
+			return err
+		}
+		return errors.New("failed")
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, int64(0), testTxCount(t, suite.DB()))
+}
+
+func TestTransactional_ExpectedBehavior(t *testing.T) {
+	db := testGormDB(t, &testRepoModel{})
+	create := func(status int) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			if err := NewRepository[testRepoModel](DriverFrom(c, db)).Create(&testRepoModel{Username: c.Query("name")}); err != nil {
+				return err
+			}
+			if status >= http.StatusBadRequest {
+				return resp.New(c).ErrorWithStatus("failed", errors.New("failed"), status)
+			}
+			return c.SendStatus(status)
+		}
+	}
+
+	app := fiber.New()
+	app.Post("/ok", Transactional(db), create(http.StatusCreated))
+	app.Post("/error-response", Transactional(db), create(http.StatusConflict))
+	app.Post("/error", Transactional(db), func(c *fiber.Ctx) error {
+		_ = create(http.StatusOK)(c)
+		return fiber.ErrTeapot
+	})
+	app.Get("/tx", func(c *fiber.Ctx) error {
+		_, ok := TxFrom(c)
+		return c.JSON(ok)
+	})
+
+	for path, status := range map[string]int{
+		"/ok?name=alice":           http.StatusCreated,
+		"/error-response?name=bob": http.StatusConflict,
+		"/error?name=carol":        http.StatusTeapot,
+	} {
+		res, err := app.Test(httptest.NewRequest("POST", path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, status, res.StatusCode, path)
+	}
+
+	models, _ := NewRepository[testRepoModel](NewGormRepository(db)).FindBy()
+	assert.Len(t, models, 1)
+	assert.Equal(t, "alice", models[0].Username)
+
+	res, err := app.Test(httptest.NewRequest("GET", "/tx", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	assert.Equal(t, "false", string(body))
+}
@@ -0,0 +1,295 @@
+package trex
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// AssertContentType will check the response's media type, ignoring parameters like charset, and fail if it does not match.
+func (tr *TestResponse) AssertContentType(mediaType string) *TestResponse {
+	got := tr.mediaType()
+	require.Equalf(tr.suite.T(), mediaType, got, "wanted content type: %v, got: %v", mediaType, got)
+	return tr
+}
+
+// DecodeBody decodes the response body into v based on the response's content type. Supports JSON, XML, MessagePack and CSV.
+//
+// XML and MessagePack follow the json struct tags. XML decodes into structs, or into a *map[string]interface{} / *interface{} of strings where
+// slice items are <item> elements. CSV decodes into a *[]map[string]string keyed by the header row or a *[][]string of raw records.
+func (tr *TestResponse) DecodeBody(v interface{}) error {
+	b := tr.BodyBytes()
+
+	switch mediaType := tr.mediaType(); mediaType {
+	case fiber.MIMEApplicationJSON, "application/problem+json":
+		return json.Unmarshal(b, v)
+	case fiber.MIMEApplicationXML, fiber.MIMETextXML:
+		return decodeXML(b, v)
+	case "application/msgpack", "application/x-msgpack":
+		dec := msgpack.NewDecoder(bytes.NewReader(b))
+		dec.SetCustomStructTag("json")
+		return dec.Decode(v)
+	case "text/csv":
+		return decodeCSV(b, v)
+	default:
+		return fmt.Errorf("trex: unable to decode content type: %s", mediaType)
+	}
+}
+
+// mediaType returns the response's content type without parameters.
+func (tr *TestResponse) mediaType() string {
+	if tr.response == nil {
+		return ""
+	}
+	contentType := tr.response.Header.Get(fiber.HeaderContentType)
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return mediaType
+}
+
+// decodeXML decodes into generic values when given a map or interface pointer. Other values are decoded through JSON, like resp.EncodeXML encodes them,
+// so the json struct tags are followed.
+func decodeXML(b []byte, v interface{}) error {
+	generic, err := decodeXMLGeneric(b)
+	if err != nil {
+		return err
+	}
+
+	switch out := v.(type) {
+	case *map[string]interface{}:
+		m, ok := generic.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("trex: xml root is not an object")
+		}
+		*out = m
+		return nil
+	case *interface{}:
+		*out = generic
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("trex: unable to decode xml into %T", v)
+	}
+
+	j, err := json.Marshal(xmlToJSON(generic, rv.Type().Elem()))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(j, v)
+}
+
+// xmlToJSON converts the decoded XML strings into the JSON values expected by the type, e.g. numbers for numeric fields. Empty elements become null.
+func xmlToJSON(v interface{}, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if s, ok := v.(string); ok && s == "" && t.Kind() != reflect.String && t.Kind() != reflect.Interface {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		fields := jsonFields(t)
+		out := make(map[string]interface{}, len(m))
+		for name, value := range m {
+			if ft, ok := fields[name]; ok {
+				out[name] = xmlToJSON(value, ft)
+			}
+		}
+		return out
+	case reflect.Map:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		out := make(map[string]interface{}, len(m))
+		for name, value := range m {
+			out[name] = xmlToJSON(value, t.Elem())
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		items, ok := v.([]interface{})
+		if !ok {
+			return v
+		}
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			out[i] = xmlToJSON(item, t.Elem())
+		}
+		return out
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if s, ok := v.(string); ok {
+			return json.Number(s)
+		}
+	case reflect.Bool:
+		if s, ok := v.(string); ok {
+			if b, err := strconv.ParseBool(s); err == nil {
+				return b
+			}
+		}
+	}
+	return v
+}
+
+// jsonFields maps the JSON names of a struct's fields, including promoted fields of embedded structs, to their types.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			for embedded, et := range jsonFields(ft) {
+				if _, ok := fields[embedded]; !ok {
+					fields[embedded] = et
+				}
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+// decodeXMLGeneric decodes the root element's content into maps, slices and strings.
+func decodeXMLGeneric(b []byte) (interface{}, error) {
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := tok.(xml.StartElement); ok {
+			return decodeXMLElement(dec)
+		}
+	}
+}
+
+// decodeXMLElement decodes the content of the current element. Elements with only <item> children become slices, other children become maps.
+func decodeXMLElement(dec *xml.Decoder) (interface{}, error) {
+	var (
+		text     strings.Builder
+		names    []string
+		children []interface{}
+	)
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(dec)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, xmlElementKey(t))
+			children = append(children, child)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			if len(children) == 0 {
+				return strings.TrimSpace(text.String()), nil
+			}
+
+			isList := true
+			for _, name := range names {
+				if name != "item" {
+					isList = false
+					break
+				}
+			}
+			if isList {
+				return children, nil
+			}
+
+			m := make(map[string]interface{}, len(children))
+			for i, name := range names {
+				m[name] = children[i]
+			}
+			return m, nil
+		}
+	}
+}
+
+// xmlElementKey is the key of an element: its name, or the key attribute of the <entry key="..."> elements used by resp.EncodeXML for keys that are not valid names.
+func xmlElementKey(el xml.StartElement) string {
+	if el.Name.Local == "entry" {
+		for _, attr := range el.Attr {
+			if attr.Name.Local == "key" {
+				return attr.Value
+			}
+		}
+	}
+	return el.Name.Local
+}
+
+// decodeCSV decodes records keyed by the header row, or the raw records.
+func decodeCSV(b []byte, v interface{}) error {
+	records, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+	if err != nil {
+		return err
+	}
+
+	switch out := v.(type) {
+	case *[][]string:
+		*out = records
+		return nil
+	case *[]map[string]string:
+		rows := make([]map[string]string, 0)
+		if len(records) > 0 {
+			header := records[0]
+			for _, record := range records[1:] {
+				row := make(map[string]string, len(header))
+				for i, col := range header {
+					row[col] = record[i]
+				}
+				rows = append(rows, row)
+			}
+		}
+		*out = rows
+		return nil
+	default:
+		return fmt.Errorf("trex: unable to decode csv into %T", v)
+	}
+}
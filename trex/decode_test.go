@@ -0,0 +1,119 @@
+package trex
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/netr/napi/resp"
+	"github.com/stretchr/testify/assert"
+)
+
+type testDecodeUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func newTestDecodeSuite(t *testing.T) *mockSuite {
+	mock := newMockSuite(t)
+	mock.app.Get("/users", func(c *fiber.Ctx) error {
+		return resp.New(c).Success("users", []testDecodeUser{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}})
+	})
+	return mock
+}
+
+func testAcceptHeader(mediaType string) *http.Header {
+	headers := http.Header{}
+	headers.Set(fiber.HeaderAccept, mediaType)
+	return &headers
+}
+
+func Test_TestResponse_DecodeBody_Json(t *testing.T) {
+	tr := New(newTestDecodeSuite(t)).Get("/users", nil).AssertContentType(fiber.MIMEApplicationJSON)
+
+	var res SuccessResponse
+	handleError(t, tr.DecodeBody(&res))
+	assert.Equal(t, "users", res.Message)
+	assert.Len(t, res.Data, 2)
+}
+
+func Test_TestResponse_DecodeBody_Xml(t *testing.T) {
+	tr := New(newTestDecodeSuite(t)).Get("/users", testAcceptHeader(fiber.MIMEApplicationXML)).AssertContentType(fiber.MIMEApplicationXML)
+
+	var res map[string]interface{}
+	handleError(t, tr.DecodeBody(&res))
+	assert.Equal(t, "users", res["message"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": "1", "name": "alice"},
+		map[string]interface{}{"id": "2", "name": "bob"},
+	}, res["data"])
+}
+
+func Test_TestResponse_DecodeBody_XmlEntries(t *testing.T) {
+	mock := newMockSuite(t)
+	mock.app.Get("/stats", func(c *fiber.Ctx) error {
+		return resp.New(c).Success("stats", map[string]int{"2024": 3, "per day": 1, "total": 4})
+	})
+	tr := New(mock).Get("/stats", testAcceptHeader(fiber.MIMEApplicationXML))
+
+	var res map[string]interface{}
+	handleError(t, tr.DecodeBody(&res))
+	assert.Equal(t, map[string]interface{}{"2024": "3", "per day": "1", "total": "4"}, res["data"])
+}
+
+func Test_TestResponse_DecodeBody_XmlIntoStruct(t *testing.T) {
+	tr := New(newTestDecodeSuite(t)).Get("/users", testAcceptHeader(fiber.MIMEApplicationXML))
+
+	var res struct {
+		Data    []testDecodeUser `json:"data"`
+		Message string           `json:"message"`
+	}
+	handleError(t, tr.DecodeBody(&res))
+	assert.Equal(t, "users", res.Message)
+	assert.Equal(t, []testDecodeUser{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}}, res.Data)
+}
+
+func Test_TestResponse_AssertPaginationMeta_Xml(t *testing.T) {
+	mock := newMockSuite(t)
+	mock.app.Get("/users", func(c *fiber.Ctx) error {
+		return resp.New(c).Paginated("users", []testDecodeUser{{ID: 1, Name: "alice"}}, resp.PaginationMeta{Page: 2, Limit: 1, Total: 3, LastPage: 3, From: 2, To: 2})
+	})
+
+	New(mock).Get("/users", testAcceptHeader(fiber.MIMEApplicationXML)).
+		AssertContentType(fiber.MIMEApplicationXML).
+		AssertPaginationMeta(PaginationMeta{Page: 2, Limit: 1, Total: 3, LastPage: 3, From: 2, To: 2})
+}
+
+func Test_TestResponse_DecodeBody_Msgpack(t *testing.T) {
+	tr := New(newTestDecodeSuite(t)).Get("/users", testAcceptHeader(resp.MIMEApplicationMsgpack)).AssertContentType(resp.MIMEApplicationMsgpack)
+
+	var res struct {
+		Data    []testDecodeUser `json:"data"`
+		Message string           `json:"message"`
+	}
+	handleError(t, tr.DecodeBody(&res))
+	assert.Equal(t, "users", res.Message)
+	assert.Equal(t, []testDecodeUser{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}}, res.Data)
+}
+
+func Test_TestResponse_DecodeBody_Csv(t *testing.T) {
+	tr := New(newTestDecodeSuite(t)).Get("/users", testAcceptHeader(resp.MIMETextCSV)).AssertContentType(resp.MIMETextCSV)
+
+	var rows []map[string]string
+	handleError(t, tr.DecodeBody(&rows))
+	assert.Equal(t, []map[string]string{{"id": "1", "name": "alice"}, {"id": "2", "name": "bob"}}, rows)
+
+	var records [][]string
+	handleError(t, tr.DecodeBody(&records))
+	assert.Equal(t, []string{"id", "name"}, records[0])
+}
+
+func Test_TestResponse_DecodeBody_ShouldFailOnUnknownContentType(t *testing.T) {
+	mock := newMockSuite(t)
+	mock.app.Get("/text", func(c *fiber.Ctx) error {
+		return c.SendString("plain")
+	})
+
+	var res interface{}
+	assert.Error(t, New(mock).Get("/text", nil).DecodeBody(&res))
+}
@@ -0,0 +1,69 @@
+package trex
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+// linkPattern matches a single `<url>; rel="name"` entry of an RFC 8288 Link header.
+var linkPattern = regexp.MustCompile(`<([^>]*)>\s*;\s*rel="?([^",;]+)"?`)
+
+// ParsePaginated will parse the response body and convert it into a PaginatedResponse
+func (tr *TestResponse) ParsePaginated() (*PaginatedResponse, error) {
+	res := &PaginatedResponse{}
+	if err := tr.DecodeBody(res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// AssertPaginationMeta checks the meta block of a PaginatedResponse and fails if it does not match.
+func (tr *TestResponse) AssertPaginationMeta(expected PaginationMeta) *TestResponse {
+	res, err := tr.ParsePaginated()
+	if err != nil {
+		tr.suite.T().Fatal(err)
+		return tr
+	}
+
+	require.Equalf(tr.suite.T(), expected, res.Meta, "wanted meta: %+v, got: %+v", expected, res.Meta)
+	return tr
+}
+
+// AssertLinkHeader checks the URL of a relation in the Link header, e.g. AssertLinkHeader("next", "/accounts?limit=10&page=3").
+// Expected URLs starting with "/" are compared against the path and query only. An empty URL asserts that the relation is missing.
+func (tr *TestResponse) AssertLinkHeader(rel, expected string) *TestResponse {
+	links := tr.Links()
+	got, ok := links[rel]
+
+	if expected == "" {
+		require.Falsef(tr.suite.T(), ok, "wanted no %q link, got: %v", rel, got)
+		return tr
+	}
+	require.Truef(tr.suite.T(), ok, "wanted %q link, got: %v", rel, links)
+
+	if strings.HasPrefix(expected, "/") {
+		if u, err := url.Parse(got); err == nil {
+			got = u.RequestURI()
+		}
+	}
+	require.Equalf(tr.suite.T(), expected, got, "wanted %q link: %v, got: %v", rel, expected, got)
+	return tr
+}
+
+// Links returns the URLs of the response's Link header keyed by relation.
+func (tr *TestResponse) Links() map[string]string {
+	links := map[string]string{}
+	if tr.response == nil {
+		return links
+	}
+
+	for _, header := range tr.response.Header.Values("Link") {
+		for _, match := range linkPattern.FindAllStringSubmatch(header, -1) {
+			links[match[2]] = match[1]
+		}
+	}
+	return links
+}
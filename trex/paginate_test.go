@@ -0,0 +1,32 @@
+package trex
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/netr/napi/resp"
+)
+
+func newTestPaginatedSuite(t *testing.T) *mockSuite {
+	mock := newMockSuite(t)
+	mock.app.Get("/users", func(c *fiber.Ctx) error {
+		return resp.New(c).Paginated("users", []string{"a", "b"}, resp.NewPaginationMeta(1, 2, 3))
+	})
+	return mock
+}
+
+func Test_TestResponse_AssertPaginationMeta(t *testing.T) {
+	New(newTestPaginatedSuite(t)).
+		Get("/users?page=1&limit=2", nil).
+		AssertOk().
+		AssertPaginationMeta(PaginationMeta{Page: 1, Limit: 2, Total: 3, LastPage: 2, From: 1, To: 2})
+}
+
+func Test_TestResponse_AssertLinkHeader(t *testing.T) {
+	New(newTestPaginatedSuite(t)).
+		Get("/users?page=1&limit=2", nil).
+		AssertLinkHeader("first", "/users?limit=2&page=1").
+		AssertLinkHeader("next", "http://example.com/users?limit=2&page=2").
+		AssertLinkHeader("last", "/users?limit=2&page=2").
+		AssertLinkHeader("prev", "")
+}
@@ -57,3 +57,18 @@ func (j FormErrorResponse) Contains(fields ...string) bool {
 
 	return needs == found
 }
+
+type PaginationMeta struct {
+	Page     int   `json:"page"`
+	Limit    int   `json:"limit"`
+	Total    int64 `json:"total"`
+	LastPage int   `json:"last_page"`
+	From     int   `json:"from"`
+	To       int   `json:"to"`
+}
+
+type PaginatedResponse struct {
+	Data    interface{}    `json:"data"`
+	Meta    PaginationMeta `json:"meta"`
+	Message string         `json:"message"`
+}
@@ -3,6 +3,7 @@ package napi
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"unicode"
 
@@ -38,6 +39,35 @@ func (ve ValidationError) Error() map[string]string {
 	return errorBag{"error": ve.error.Error()}
 }
 
+// Err returns the validation error as an error, e.g. to be returned from a handler, or nil if there is none.
+func (ve *ValidationError) Err() error {
+	if ve == nil {
+		return nil
+	}
+	return &ValidationFailedError{Validation: ve}
+}
+
+// ValidationFailedError is a ValidationError returned as an error, made by ValidationError.Err. The ErrorRegistry sends it as a 422 resp.FormErrorResponse.
+type ValidationFailedError struct {
+	Validation *ValidationError
+}
+
+// Error joins the validation messages, sorted by field name.
+func (e *ValidationFailedError) Error() string {
+	bag := e.Validation.Error()
+	fields := make([]string, 0, len(bag))
+	for field := range bag {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	msgs := make([]string, len(fields))
+	for i, field := range fields {
+		msgs[i] = fmt.Sprintf("%s: %s", field, bag[field])
+	}
+	return strings.Join(msgs, "; ")
+}
+
 // //////////////////////
 // ERROR TRANSLATIONS
 // //////////////////////